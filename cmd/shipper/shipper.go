@@ -19,10 +19,21 @@ func main() {
 			shippercli.Release,
 			shippercli.Remove,
 			shippercli.CI,
+			shippercli.Auth,
+			shippercli.Cache,
+			shippercli.Lock,
+			shippercli.Unlock,
+			shippercli.Freeze,
+			shippercli.Unfreeze,
+			shippercli.Approve,
+			shippercli.Status,
+			shippercli.Render,
+			shippercli.Validate,
 		},
 	}
 
 	if err := app.Run(os.Args); err != nil {
-		fmt.Printf("%s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+		os.Exit(1)
 	}
 }
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v45/github"
+	"golang.org/x/oauth2"
+)
+
+// App credentials authenticate as a Github App. Installation tokens are
+// minted per repo and refreshed when they expire.
+type App struct {
+	id  int64
+	key *rsa.PrivateKey
+
+	mu      sync.Mutex
+	sources map[string]oauth2.TokenSource
+}
+
+// NewApp sets up Github App credentials from the app ID and PEM encoded
+// private key.
+func NewApp(id int64, privateKey []byte) (*App, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, fmt.Errorf("github app private key is not PEM encoded")
+	}
+
+	key, err := parsePrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse github app private key: %w", err)
+	}
+
+	return &App{
+		id:      id,
+		key:     key,
+		sources: map[string]oauth2.TokenSource{},
+	}, nil
+}
+
+func parsePrivateKey(der []byte) (*rsa.PrivateKey, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+
+	return rsaKey, nil
+}
+
+// TokenSource for the repo. Tokens are cached until they expire.
+func (a *App) TokenSource(owner, repo string) oauth2.TokenSource {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	k := owner + "/" + repo
+
+	if ts, ok := a.sources[k]; ok {
+		return ts
+	}
+
+	ts := oauth2.ReuseTokenSource(nil, &installationTokenSource{app: a, owner: owner, repo: repo})
+	a.sources[k] = ts
+
+	return ts
+}
+
+// client authenticated as the app itself, used to mint installation tokens
+func (a *App) client() (*github.Client, error) {
+	jwt, err := a.jwt(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return github.NewClient(&http.Client{
+		Transport: &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: jwt, TokenType: "Bearer"}),
+		},
+	}), nil
+}
+
+// jwt builds a short lived token signed with the app private key
+func (a *App) jwt(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := json.Marshal(map[string]any{
+		// backdated to allow for clock drift
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": strconv.FormatInt(a.id, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign jwt: %w", err)
+	}
+
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// AppProvider provides Github App credentials when an app ID is given
+type AppProvider struct {
+	ID int64
+
+	// Key is a path to the private key, or the PEM encoded key itself
+	Key string
+}
+
+// Name of the provider
+func (p *AppProvider) Name() string {
+	if p.ID == 0 {
+		return "github app"
+	}
+
+	return fmt.Sprintf("github app %d", p.ID)
+}
+
+// Credentials for the app
+func (p *AppProvider) Credentials(ctx context.Context) (Credentials, error) {
+	if p.ID == 0 {
+		return nil, nil
+	}
+
+	if p.Key == "" {
+		return nil, fmt.Errorf("a private key is required for github app %d", p.ID)
+	}
+
+	key := []byte(p.Key)
+
+	if !strings.HasPrefix(strings.TrimSpace(p.Key), "-----BEGIN") {
+		var err error
+
+		key, err = os.ReadFile(p.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read github app key: %w", err)
+		}
+	}
+
+	return NewApp(p.ID, key)
+}
+
+// installationTokenSource mints installation tokens scoped to a single repo
+type installationTokenSource struct {
+	app   *App
+	owner string
+	repo  string
+}
+
+// Token mints a new installation token
+func (s *installationTokenSource) Token() (*oauth2.Token, error) {
+	ctx := context.Background()
+
+	client, err := s.app.client()
+	if err != nil {
+		return nil, err
+	}
+
+	inst, _, err := client.Apps.FindRepositoryInstallation(ctx, s.owner, s.repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find github app installation for %s/%s: %w", s.owner, s.repo, err)
+	}
+
+	tok, _, err := client.Apps.CreateInstallationToken(ctx, inst.GetID(), &github.InstallationTokenOptions{
+		Repositories: []string{s.repo},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create installation token for %s/%s: %w", s.owner, s.repo, err)
+	}
+
+	return &oauth2.Token{
+		AccessToken: tok.GetToken(),
+		Expiry:      tok.GetExpiresAt(),
+	}, nil
+}
@@ -0,0 +1,59 @@
+// Package auth provides credentials for accessing Github repositories through
+// both the API and git.
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/google/go-github/v45/github"
+	"golang.org/x/oauth2"
+)
+
+// Credentials provide access tokens for Github repositories
+type Credentials interface {
+	// TokenSource returns tokens scoped to access the given repo
+	TokenSource(owner, repo string) oauth2.TokenSource
+}
+
+// Token credentials use a single access token for every repo
+type Token string
+
+// TokenSource for the repo
+func (t Token) TokenSource(owner, repo string) oauth2.TokenSource {
+	return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: string(t)})
+}
+
+// NewGithubClient sets up a github API client using the token source
+func NewGithubClient(ts oauth2.TokenSource) *github.Client {
+	return github.NewClient(oauth2.NewClient(context.Background(), ts))
+}
+
+// GitAuth builds git basic auth from the current token of the token source
+func GitAuth(ts oauth2.TokenSource) (*http.BasicAuth, error) {
+	tok, err := ts.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get github token: %w", err)
+	}
+
+	return &http.BasicAuth{Username: "x-access-token", Password: tok.AccessToken}, nil
+}
+
+// SplitRepo splits a repo of the form 'github.com/org/repo' into its owner
+// and name.
+func SplitRepo(repo string) (string, string, error) {
+	repo = strings.TrimPrefix(repo, "https://")
+
+	if !strings.HasPrefix(repo, "github.com/") {
+		return "", "", fmt.Errorf("repo '%s' must be a github repo", repo)
+	}
+
+	owner, name, found := strings.Cut(strings.TrimPrefix(repo, "github.com/"), "/")
+	if !found {
+		return "", "", fmt.Errorf("repo '%s' must be of form 'github.com/org/repo'", repo)
+	}
+
+	return owner, strings.TrimSuffix(name, ".git"), nil
+}
@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v45/github"
+)
+
+// Identity looks up who the credentials belong to on github
+type Identity struct {
+	client *github.Client
+	user   *github.User
+
+	// err is returned by every lookup if the credentials have no user
+	err error
+}
+
+// NewIdentity sets up an identity using the credentials. Github App
+// credentials have no user, so lookups with them fail.
+func NewIdentity(creds Credentials) *Identity {
+	if r, ok := creds.(*Resolved); ok {
+		creds = r.Credentials
+	}
+
+	if _, ok := creds.(*App); ok {
+		return &Identity{err: fmt.Errorf("github app credentials have no user to identify, use a personal access token")}
+	}
+
+	return &Identity{client: NewGithubClient(creds.TokenSource("", ""))}
+}
+
+// CurrentUser returns the login of the authenticated user
+func (i *Identity) CurrentUser(ctx context.Context) (string, error) {
+	user, err := i.get(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return user.GetLogin(), nil
+}
+
+// Signature returns the name and email of the authenticated user for commits,
+// using their noreply address if their email is private
+func (i *Identity) Signature(ctx context.Context) (string, string, error) {
+	user, err := i.get(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	name := user.GetName()
+	if name == "" {
+		name = user.GetLogin()
+	}
+
+	email := user.GetEmail()
+	if email == "" {
+		email = NoReplyEmail(user.GetID(), user.GetLogin())
+	}
+
+	return name, email, nil
+}
+
+// NoReplyEmail is the github noreply address of the user, which attributes
+// commits to them without revealing their email
+func NoReplyEmail(id int64, login string) string {
+	return fmt.Sprintf("%d+%s@users.noreply.github.com", id, login)
+}
+
+func (i *Identity) get(ctx context.Context) (*github.User, error) {
+	if i.err != nil {
+		return nil, i.err
+	}
+
+	if i.user != nil {
+		return i.user, nil
+	}
+
+	user, _, err := i.client.Users.Get(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authenticated github user: %w", err)
+	}
+
+	i.user = user
+
+	return user, nil
+}
+
+// IsTeamMember returns true if the user is an active member of the team,
+// given as org/team-slug
+func (i *Identity) IsTeamMember(ctx context.Context, team, user string) (bool, error) {
+	if i.err != nil {
+		return false, i.err
+	}
+
+	org, slug, found := strings.Cut(team, "/")
+	if !found {
+		return false, fmt.Errorf("team '%s' must be of form 'org/team-slug'", team)
+	}
+
+	m, resp, err := i.client.Teams.GetTeamMembershipBySlug(ctx, org, slug, user)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to get membership of %s: %w", team, err)
+	}
+
+	return m.GetState() == "active", nil
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Provider looks up credentials from a single place
+type Provider interface {
+	// Name describes where the credentials come from
+	Name() string
+
+	// Credentials returns nil if the provider has none
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// Resolved credentials along with the provider they came from
+type Resolved struct {
+	Credentials
+	Source string
+}
+
+// Resolve tries each provider in order, returning the first credentials found
+func Resolve(ctx context.Context, providers ...Provider) (*Resolved, error) {
+	tried := make([]string, 0, len(providers))
+
+	for _, p := range providers {
+		creds, err := p.Credentials(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get credentials from %s: %w", p.Name(), err)
+		}
+
+		if creds != nil {
+			return &Resolved{Credentials: creds, Source: p.Name()}, nil
+		}
+
+		tried = append(tried, p.Name())
+	}
+
+	return nil, fmt.Errorf("no github credentials found, tried: %s", strings.Join(tried, ", "))
+}
+
+// StaticProvider provides a token that was given directly, e.g. by a flag
+type StaticProvider struct {
+	Label string
+	Token string
+}
+
+// Name of the provider
+func (p *StaticProvider) Name() string {
+	return p.Label
+}
+
+// Credentials from the given token
+func (p *StaticProvider) Credentials(ctx context.Context) (Credentials, error) {
+	if p.Token == "" {
+		return nil, nil
+	}
+
+	return Token(p.Token), nil
+}
+
+// EnvProvider provides a token from an environment variable
+type EnvProvider struct {
+	Var string
+}
+
+// Name of the provider
+func (p *EnvProvider) Name() string {
+	return fmt.Sprintf("env %s", p.Var)
+}
+
+// Credentials from the environment
+func (p *EnvProvider) Credentials(ctx context.Context) (Credentials, error) {
+	if v := os.Getenv(p.Var); v != "" {
+		return Token(v), nil
+	}
+
+	return nil, nil
+}
+
+// GhConfigProvider provides the token stored by the gh CLI in its hosts.yml
+type GhConfigProvider struct {
+	Host string
+}
+
+// Name of the provider
+func (p *GhConfigProvider) Name() string {
+	return "gh config " + ghHostsFile()
+}
+
+// Credentials from the gh hosts file
+func (p *GhConfigProvider) Credentials(ctx context.Context) (Credentials, error) {
+	bts, err := os.ReadFile(ghHostsFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	hosts := map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}{}
+
+	if err := yaml.Unmarshal(bts, &hosts); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ghHostsFile(), err)
+	}
+
+	// newer versions of gh keep the token in the system keyring instead
+	if tok := hosts[p.Host].OAuthToken; tok != "" {
+		return Token(tok), nil
+	}
+
+	return nil, nil
+}
+
+func ghHostsFile() string {
+	dir := os.Getenv("GH_CONFIG_DIR")
+
+	if dir == "" {
+		if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+			dir = filepath.Join(xdg, "gh")
+		} else {
+			home, _ := os.UserHomeDir()
+			dir = filepath.Join(home, ".config", "gh")
+		}
+	}
+
+	return filepath.Join(dir, "hosts.yml")
+}
+
+// GitCredentialProvider asks the configured git credential helpers for the
+// password of the host, using the `git credential fill` protocol.
+type GitCredentialProvider struct {
+	Host string
+}
+
+// Name of the provider
+func (p *GitCredentialProvider) Name() string {
+	return "git credential helper"
+}
+
+// Credentials from git
+func (p *GitCredentialProvider) Credentials(ctx context.Context) (Credentials, error) {
+	_, password := GitCredentialFill(ctx, p.Host)
+	if password == "" {
+		return nil, nil
+	}
+
+	return Token(password), nil
+}
+
+// GitCredentialFill asks the git credential helpers for the username and
+// password of a host. Empty strings are returned if there are none.
+func GitCredentialFill(ctx context.Context, host string) (string, string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", ""
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=https\nhost=%s\n\n", host))
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_ASKPASS=true")
+
+	out, err := cmd.Output()
+	if err != nil {
+		// git exits non zero when no helper has credentials for the host
+		return "", ""
+	}
+
+	var username, password string
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		k, v, _ := strings.Cut(scanner.Text(), "=")
+
+		switch k {
+		case "username":
+			username = v
+		case "password":
+			password = v
+		}
+	}
+
+	return username, password
+}
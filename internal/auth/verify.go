@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/cygnetdigital/shipper/internal/sshsig"
+	"github.com/google/go-github/v45/github"
+	"golang.org/x/crypto/ssh"
+)
+
+// VerifySignature returns true if the armored ssh or gpg signature of the
+// message was made with one of the keys on the user's github account
+func (i *Identity) VerifySignature(ctx context.Context, user string, message []byte, signature string) (bool, error) {
+	if i.err != nil {
+		return false, i.err
+	}
+
+	if sshsig.IsArmored(signature) {
+		keys, err := i.sshKeys(ctx, user)
+		if err != nil {
+			return false, err
+		}
+
+		return sshsig.Verify(keys, message, signature), nil
+	}
+
+	keys, err := i.gpgKeys(ctx, user)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = openpgp.CheckArmoredDetachedSignature(keys, bytes.NewReader(message), strings.NewReader(signature), nil)
+
+	return err == nil, nil
+}
+
+// sshKeys are the user's public ssh keys
+func (i *Identity) sshKeys(ctx context.Context, user string) ([]ssh.PublicKey, error) {
+	keys := []ssh.PublicKey{}
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		page, resp, err := i.client.Users.ListKeys(ctx, user, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list ssh keys of %s: %w", user, err)
+		}
+
+		for _, k := range page {
+			pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(k.GetKey()))
+			if err == nil {
+				keys = append(keys, pub)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return keys, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+// gpgKeys are the user's public gpg keys
+func (i *Identity) gpgKeys(ctx context.Context, user string) (openpgp.EntityList, error) {
+	keys := openpgp.EntityList{}
+	opts := &github.ListOptions{PerPage: 100}
+
+	for {
+		page, resp, err := i.client.Users.ListGPGKeys(ctx, user, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list gpg keys of %s: %w", user, err)
+		}
+
+		for _, k := range page {
+			entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(k.GetRawKey()))
+			if err == nil {
+				keys = append(keys, entities...)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return keys, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/auth"
+	"github.com/cygnetdigital/shipper/internal/source"
+	"github.com/cygnetdigital/shipper/pkg/handler"
+	"github.com/urfave/cli/v2"
+)
+
+// Approve command
+var Approve = &cli.Command{
+	Name:        "approve",
+	Usage:       "approve a release which needs approval",
+	Description: "e.g. `shipper approve service.foo v3`",
+	ArgsUsage:   "[service] [version]",
+	Flags: append(append(githubFlags(), gitopsFlags()...),
+		&cli.StringFlag{
+			Name:  "signing-key",
+			Usage: "private key to sign the approval with, one of the ssh or gpg keys on your github account",
+			Value: "~/.ssh/id_ed25519",
+			EnvVars: []string{
+				"SHIPPER_SIGNING_KEY",
+			},
+		},
+		&cli.StringFlag{
+			Name:  "signing-format",
+			Usage: "format of the signing key, either ssh or gpg",
+			Value: "ssh",
+			EnvVars: []string{
+				"SHIPPER_SIGNING_FORMAT",
+			},
+		},
+		&cli.StringFlag{
+			Name:  "signing-passphrase",
+			Usage: "passphrase of the signing key",
+			EnvVars: []string{
+				"SHIPPER_SIGNING_PASSPHRASE",
+			},
+		},
+	),
+	Action: func(c *cli.Context) error {
+		service := c.Args().First()
+		if service == "" {
+			return fmt.Errorf("service is required")
+		}
+
+		creds, err := githubCredentials(c)
+		if err != nil {
+			return err
+		}
+
+		pwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working dir: %w", err)
+		}
+
+		proj, err := shipper.LoadProject(pwd)
+		if err != nil {
+			return fmt.Errorf("failed to get project context: %w", err)
+		}
+
+		cache, err := repoCache(c)
+		if err != nil {
+			return err
+		}
+
+		dest, err := newDestination(c, proj, creds, cache)
+		if err != nil {
+			return err
+		}
+
+		src, err := source.NewGithub(proj, creds, cache)
+		if err != nil {
+			return err
+		}
+
+		// the policy comes from the reviewed config, not the local copy
+		trusted, err := src.DefaultProject(c.Context, proj.Name)
+		if err != nil {
+			return fmt.Errorf("failed to load project from its default branch: %w", err)
+		}
+
+		signer, err := newSigner(c.String("signing-format"), c.String("signing-key"), c.String("signing-passphrase"))
+		if err != nil {
+			return err
+		}
+
+		hand := &handler.LocalHandler{
+			Dest:     dest,
+			Approval: trusted.Approval,
+			Identity: auth.NewIdentity(creds),
+			Signer:   signer,
+		}
+
+		ares, err := hand.Approve(c.Context, &handler.ApproveParams{
+			Project: proj.Name,
+			Service: service,
+			Version: c.Args().Get(1),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to approve: %w", err)
+		}
+
+		a := ares.Approval
+
+		fmt.Printf("✅  Approved %s of %s/%s → %s (%d of %d)\n", a.Operation, a.Project, a.Service, a.Version, ares.Status.Have, ares.Status.Need)
+
+		return nil
+	},
+}
@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/auth"
+	"github.com/urfave/cli/v2"
+)
+
+// Auth command
+var Auth = &cli.Command{
+	Name:  "auth",
+	Usage: "github authentication helper commands",
+	Flags: []cli.Flag{},
+	Subcommands: []*cli.Command{
+		{
+			Name:        "status",
+			Usage:       "show which github credentials would be used",
+			Description: "e.g. `shipper auth status`",
+			Flags:       githubFlags(),
+			Action: func(c *cli.Context) error {
+				creds, err := githubCredentials(c)
+				if err != nil {
+					return err
+				}
+
+				fmt.Printf("🔑  Using credentials from %s\n", creds.Source)
+
+				if _, ok := creds.Credentials.(*auth.App); ok {
+					return appStatus(c, creds)
+				}
+
+				client := auth.NewGithubClient(creds.TokenSource("", ""))
+
+				user, resp, err := client.Users.Get(c.Context, "")
+				if err != nil {
+					return fmt.Errorf("failed to get authenticated user: %w", err)
+				}
+
+				scopes := resp.Header.Get("X-OAuth-Scopes")
+				if scopes == "" {
+					scopes = "none reported"
+				}
+
+				fmt.Printf("👤  Authenticated as %s\n", user.GetLogin())
+				fmt.Printf("🔐  Scopes: %s\n", scopes)
+
+				return nil
+			},
+		},
+	},
+}
+
+// appStatus mints installation tokens for the project repos, as apps have no
+// user or scopes of their own.
+func appStatus(c *cli.Context, creds *auth.Resolved) error {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get working dir: %w", err)
+	}
+
+	proj, err := shipper.LoadProject(pwd)
+	if err != nil {
+		fmt.Println("🤷  Not in a project, so no installation tokens were checked")
+
+		return nil
+	}
+
+	for _, repo := range []string{proj.Repo, proj.Gitops.Repo} {
+		owner, name, err := auth.SplitRepo(repo)
+		if err != nil {
+			return err
+		}
+
+		tok, err := creds.TokenSource(owner, name).Token()
+		if err != nil {
+			fmt.Printf("❌  %s: %s\n", repo, err)
+
+			continue
+		}
+
+		fmt.Printf("✅  %s: installation token expires %s\n", repo, tok.Expiry.Format("15:04:05"))
+	}
+
+	return nil
+}
+
+// githubFlags are the flags used to authenticate with github
+func githubFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "github-token",
+			Usage: "github token, otherwise looked up from SHIPPER_GITHUB_TOKEN, gh or git credentials",
+		},
+		&cli.Int64Flag{
+			Name:  "github-app-id",
+			Usage: "authenticate as a github app, unless a github token is set",
+			EnvVars: []string{
+				"SHIPPER_GITHUB_APP_ID",
+			},
+		},
+		&cli.StringFlag{
+			Name:  "github-app-key",
+			Usage: "path to the github app private key, or the PEM encoded key itself",
+			EnvVars: []string{
+				"SHIPPER_GITHUB_APP_KEY",
+			},
+		},
+	}
+}
+
+// githubCredentials resolves credentials by trying, in order: the token flag,
+// the token env var, a github app, the gh CLI config and finally the git
+// credential helpers.
+func githubCredentials(c *cli.Context) (*auth.Resolved, error) {
+	host := "github.com"
+
+	// prefer the host of the project repo if we are in one
+	if pwd, err := os.Getwd(); err == nil {
+		if proj, err := shipper.LoadProject(pwd); err == nil && proj.Repo != "" {
+			host = strings.Split(strings.TrimPrefix(proj.Repo, "https://"), "/")[0]
+		}
+	}
+
+	return auth.Resolve(c.Context,
+		&auth.StaticProvider{Label: "flag --github-token", Token: c.String("github-token")},
+		&auth.EnvProvider{Var: "SHIPPER_GITHUB_TOKEN"},
+		&auth.AppProvider{ID: c.Int64("github-app-id"), Key: c.String("github-app-key")},
+		&auth.GhConfigProvider{Host: host},
+		&auth.GitCredentialProvider{Host: host},
+	)
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cygnetdigital/shipper/internal/gitcache"
+	"github.com/cygnetdigital/shipper/internal/helm"
+	"github.com/urfave/cli/v2"
+)
+
+// Cache command
+var Cache = &cli.Command{
+	Name:  "cache",
+	Usage: "manage the local cache of repo mirrors and helm charts",
+	Flags: []cli.Flag{},
+	Subcommands: []*cli.Command{
+		{
+			Name:        "clean",
+			Usage:       "remove all cached repo mirrors and helm charts",
+			Description: "e.g. `shipper cache clean`",
+			Action: func(c *cli.Context) error {
+				dir, err := gitcache.DefaultDir()
+				if err != nil {
+					return err
+				}
+
+				if err := gitcache.New(dir).Clean(c.Context); err != nil {
+					return fmt.Errorf("failed to clean cache: %w", err)
+				}
+
+				fmt.Printf("🧹  Removed %s\n", dir)
+
+				charts, err := helm.DefaultDir()
+				if err != nil {
+					return err
+				}
+
+				if err := os.RemoveAll(charts); err != nil {
+					return fmt.Errorf("failed to clean chart cache: %w", err)
+				}
+
+				fmt.Printf("🧹  Removed %s\n", charts)
+
+				return nil
+			},
+		},
+	},
+}
@@ -39,6 +39,7 @@ var CI = &cli.Command{
 
 				type service struct {
 					Name       string `json:"name"`
+					Image      string `json:"image"`
 					Dockerfile string `json:"dockerfile"`
 					Path       string `json:"path"`
 				}
@@ -53,6 +54,7 @@ var CI = &cli.Command{
 
 					svcs = append(svcs, &service{
 						Name:       svc.Name,
+						Image:      svc.ImageName(),
 						Dockerfile: svc.Build.Dockerfile,
 						Path:       svc.RootDir,
 					})
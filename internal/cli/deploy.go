@@ -7,7 +7,7 @@ import (
 
 	"github.com/cygnetdigital/shipper"
 	"github.com/cygnetdigital/shipper/internal/cliutil"
-	"github.com/cygnetdigital/shipper/internal/destination/github"
+	"github.com/cygnetdigital/shipper/internal/policy"
 	"github.com/cygnetdigital/shipper/internal/source"
 	"github.com/cygnetdigital/shipper/pkg/handler"
 	"github.com/urfave/cli/v2"
@@ -19,18 +19,11 @@ var Deploy = &cli.Command{
 	Usage:       "generate kubernetes manifests and push them to a gitops repository",
 	Description: "e.g. `shipper deploy 123` or `shipper deploy feature/foo`",
 	ArgsUsage:   "[ref]",
-	Flags: []cli.Flag{
-		&cli.StringFlag{
-			Name: "github-token",
-			EnvVars: []string{
-				"SHIPPER_GITHUB_TOKEN",
-			},
-		},
-	},
+	Flags:       append(append(append(githubFlags(), gitopsFlags()...), lockFlags()...), overrideFreezeFlag, skipValidationFlag, skipSecretCheckFlag),
 	Action: func(c *cli.Context) error {
-		ght := c.String("github-token")
-		if ght == "" {
-			return fmt.Errorf("github-token is required")
+		creds, err := githubCredentials(c)
+		if err != nil {
+			return err
 		}
 
 		pwd, err := os.Getwd()
@@ -43,17 +36,52 @@ var Deploy = &cli.Command{
 			return fmt.Errorf("failed to get project context: %w", err)
 		}
 
+		cache, err := repoCache(c)
+		if err != nil {
+			return err
+		}
+
+		dest, err := newDestination(c, proj, creds, cache)
+		if err != nil {
+			return err
+		}
+
+		src, err := source.NewGithub(proj, creds, cache)
+		if err != nil {
+			return err
+		}
+
+		// freeze windows come from the reviewed config, not the local copy
+		trusted, err := src.DefaultProject(c.Context, proj.Name)
+		if err != nil {
+			return fmt.Errorf("failed to load project from its default branch: %w", err)
+		}
+
 		hand := &handler.LocalHandler{
-			Source: source.NewGithub(proj, ght),
-			Dest:   github.NewGithub(proj, ght),
+			Source:  src,
+			Dest:    dest,
+			Freezes: trusted.Freezes,
 		}
 
+		// wait for the checks before locking, then plan under the lock
 		dp := &handler.DeployParams{
-			ProjectName: proj.Name,
-			Ref:         c.Args().First(),
+			ProjectName:    proj.Name,
+			Ref:            c.Args().First(),
+			OverrideFreeze: c.String("override-freeze"),
+			ChecksOnly:     true,
 		}
 
-		var dres *handler.DeployResp
+		var (
+			dres    *handler.DeployResp
+			unlock  func()
+			checked bool
+		)
+
+		defer func() {
+			if unlock != nil {
+				unlock()
+			}
+		}()
 
 		printer := cliutil.NewDeployPrinter()
 
@@ -64,6 +92,24 @@ var Deploy = &cli.Command{
 				return fmt.Errorf("failed to deploy: %w", err)
 			}
 
+			if !checked && len(dres.Services) > 0 {
+				if err := hand.CheckParams(c.Context, proj.Name, dres.Source); err != nil {
+					return fmt.Errorf("failed to deploy: %w", err)
+				}
+
+				checked = true
+			}
+
+			if dp.ChecksOnly && readyToPlan(dres) {
+				if unlock, err = acquireLock(c, hand, proj.Name, "deploy"); err != nil {
+					return err
+				}
+
+				dp.ChecksOnly = false
+
+				continue
+			}
+
 			if printer.Print(dres) {
 				time.Sleep(time.Second)
 
@@ -83,14 +129,19 @@ var Deploy = &cli.Command{
 			return nil
 		}
 
+		if policy.Denied(dres.Policy) {
+			return fmt.Errorf("deploy denied by policy")
+		}
+
 		resp := cliutil.StringPrompt("Deploy to production?")
 		if resp != "YES" {
 			return fmt.Errorf("aborted: only YES is accepted")
 		}
 
 		dres2, err := hand.Deploy(c.Context, &handler.DeployParams{
-			ProjectName: proj.Name,
-			Ref:         c.Args().First(),
+			ProjectName:    proj.Name,
+			Ref:            c.Args().First(),
+			OverrideFreeze: c.String("override-freeze"),
 			Confirm: &handler.ConfirmDeployParams{
 				CommitHash: dres.Source.Ref.CommitHash,
 				Requests:   buildRequestsForSerivcse(dres.Services),
@@ -110,6 +161,17 @@ var Deploy = &cli.Command{
 	},
 }
 
+// readyToPlan returns true once the checks of a deployable ref are complete
+func readyToPlan(dres *handler.DeployResp) bool {
+	ref := dres.Source.Ref
+
+	if ref.CommitHash == "" || !dres.Source.ChecksComplete || len(dres.Services) == 0 {
+		return false
+	}
+
+	return ref.PullRequest == nil || ref.PullRequest.Merged
+}
+
 // BuildRequests ...
 func buildRequestsForSerivcse(svcs []*handler.ServiceDeployStatus) (out []*handler.ServiceDeployRequest) {
 	for _, s := range svcs {
@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/auth"
+	"github.com/cygnetdigital/shipper/internal/destination/github"
+	"github.com/cygnetdigital/shipper/internal/destination/gitops"
+	"github.com/cygnetdigital/shipper/internal/gitcache"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/urfave/cli/v2"
+)
+
+// gitopsFlags are the flags used to access gitops repos that aren't on github
+func gitopsFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "gitops-ssh-key",
+			Usage: "ssh private key for the gitops repo, otherwise the ssh agent is used",
+			EnvVars: []string{
+				"SHIPPER_GITOPS_SSH_KEY",
+			},
+		},
+		&cli.StringFlag{
+			Name: "gitops-ssh-passphrase",
+			EnvVars: []string{
+				"SHIPPER_GITOPS_SSH_PASSPHRASE",
+			},
+		},
+		&cli.StringFlag{
+			Name:  "gitops-username",
+			Usage: "https username for the gitops repo, otherwise git credential helpers are used",
+			EnvVars: []string{
+				"SHIPPER_GITOPS_USERNAME",
+			},
+		},
+		&cli.StringFlag{
+			Name: "gitops-password",
+			EnvVars: []string{
+				"SHIPPER_GITOPS_PASSWORD",
+			},
+		},
+		&cli.StringFlag{
+			Name:  "gitops-signing-key",
+			Usage: "private key to sign gitops commits with, overriding the project signing key",
+			EnvVars: []string{
+				"SHIPPER_GITOPS_SIGNING_KEY",
+			},
+		},
+		&cli.StringFlag{
+			Name:  "gitops-signing-passphrase",
+			Usage: "passphrase of the gitops signing key, if it's encrypted",
+			EnvVars: []string{
+				"SHIPPER_GITOPS_SIGNING_PASSPHRASE",
+			},
+		},
+		&cli.StringFlag{
+			Name:  "age-key-file",
+			Usage: "age identities to decrypt sealed secret values with",
+			EnvVars: []string{
+				"SHIPPER_AGE_KEY_FILE",
+				"SOPS_AGE_KEY_FILE",
+			},
+		},
+		&cli.BoolFlag{
+			Name:  "no-cache",
+			Usage: "clone repos and pull charts from scratch instead of using the local caches",
+			EnvVars: []string{
+				"SHIPPER_NO_CACHE",
+			},
+		},
+	}
+}
+
+// skipValidationFlag commits rendered manifests without validating them
+var skipValidationFlag = &cli.BoolFlag{
+	Name:  "skip-validation",
+	Usage: "don't validate rendered manifests against the kubernetes schemas",
+}
+
+// skipSecretCheckFlag renders services without checking the secrets they
+// reference exist
+var skipSecretCheckFlag = &cli.BoolFlag{
+	Name:  "skip-secret-check",
+	Usage: "don't check that the secrets services reference are in the gitops repo",
+}
+
+// newDestination sets up the destination for the project gitops repo. Github
+// repos use the github credentials, anything else is accessed with ssh or
+// https credentials from the gitops flags.
+func newDestination(c *cli.Context, proj *shipper.Project, creds *auth.Resolved, cache *gitcache.Cache) (*gitops.Gitops, error) {
+	dest := newGitops(c, proj, creds, cache)
+
+	signer, err := commitSigner(c, proj)
+	if err != nil {
+		return nil, err
+	}
+
+	dest.SetCommitter(commitAuthor(proj, creds), signer)
+	dest.SetSkipValidation(c.Bool("skip-validation"))
+	dest.SetSkipSecretCheck(c.Bool("skip-secret-check"))
+	dest.SetAgeKeyFile(c.String("age-key-file"))
+	dest.SetChartCache(!c.Bool("no-cache"))
+
+	return dest, nil
+}
+
+func newGitops(c *cli.Context, proj *shipper.Project, creds *auth.Resolved, cache *gitcache.Cache) *gitops.Gitops {
+	repo := proj.Gitops.Repo
+	branch := proj.Gitops.Branch
+
+	if gitops.IsSSH(repo) {
+		sshAuth := gitops.SSHAuth(repo, c.String("gitops-ssh-key"), c.String("gitops-ssh-passphrase"))
+
+		return gitops.New(proj, gitops.NewRemote(repo, branch, sshAuth, cache))
+	}
+
+	if _, _, err := auth.SplitRepo(repo); err == nil {
+		return github.NewGithub(proj, creds, cache)
+	}
+
+	username, password := c.String("gitops-username"), c.String("gitops-password")
+
+	if password == "" {
+		if uri, err := url.Parse(gitops.NormalizeURL(repo)); err == nil {
+			username, password = auth.GitCredentialFill(c.Context, uri.Host)
+		}
+	}
+
+	return gitops.New(proj, gitops.NewRemote(repo, branch, gitops.BasicAuth(username, password), cache))
+}
+
+// commitAuthor is the configured author, or the github user. Github Apps
+// have no user, so the git config is used for them.
+func commitAuthor(proj *shipper.Project, creds *auth.Resolved) gitops.AuthorFunc {
+	if a := proj.Gitops.CommitAuthor; a.Name != "" {
+		return func(ctx context.Context) (*object.Signature, error) {
+			return &object.Signature{Name: a.Name, Email: a.Email, When: time.Now()}, nil
+		}
+	}
+
+	if _, ok := creds.Credentials.(*auth.App); ok {
+		return nil
+	}
+
+	identity := auth.NewIdentity(creds)
+
+	return func(ctx context.Context) (*object.Signature, error) {
+		name, email, err := identity.Signature(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return &object.Signature{Name: name, Email: email, When: time.Now()}, nil
+	}
+}
+
+// commitSigner loads the signing key, if commits should be signed
+func commitSigner(c *cli.Context, proj *shipper.Project) (gitops.Signer, error) {
+	signing := proj.Gitops.Signing
+
+	key := c.String("gitops-signing-key")
+	if key == "" {
+		key = signing.Key
+	}
+
+	if signing.Format == "" {
+		if key != "" {
+			return nil, fmt.Errorf("a gitops signing key is set, but the project has no signing format")
+		}
+
+		return nil, nil
+	}
+
+	if key == "" {
+		return nil, fmt.Errorf("a signing key is required to sign gitops commits")
+	}
+
+	return newSigner(signing.Format, key, c.String("gitops-signing-passphrase"))
+}
+
+// newSigner loads a gpg or ssh private key to sign with
+func newSigner(format, key, passphrase string) (gitops.Signer, error) {
+	if strings.HasPrefix(key, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+
+		key = filepath.Join(home, key[2:])
+	}
+
+	switch format {
+	case "gpg":
+		return gitops.NewGPGSigner(key, passphrase)
+	case "ssh":
+		return gitops.NewSSHSigner(key, passphrase)
+	default:
+		return nil, fmt.Errorf("unknown signing format '%s'", format)
+	}
+}
+
+// repoCache is the cache of repo mirrors, unless caching has been disabled
+func repoCache(c *cli.Context) (*gitcache.Cache, error) {
+	if c.Bool("no-cache") {
+		return nil, nil
+	}
+
+	dir, err := gitcache.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return gitcache.New(dir), nil
+}
+
+// projectDestination loads the project in the working dir and sets up its
+// destination, for commands which don't need the source
+func projectDestination(c *cli.Context) (*gitops.Gitops, *shipper.Project, error) {
+	creds, err := githubCredentials(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get working dir: %w", err)
+	}
+
+	proj, err := shipper.LoadProject(pwd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get project context: %w", err)
+	}
+
+	cache, err := repoCache(c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dest, err := newDestination(c, proj, creds, cache)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dest, proj, nil
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"github.com/cygnetdigital/shipper/internal/freeze"
+	"github.com/urfave/cli/v2"
+)
+
+// overrideFreezeFlag allows deploys and releases while services are frozen
+var overrideFreezeFlag = &cli.StringFlag{
+	Name:  "override-freeze",
+	Usage: "reason for continuing while frozen, which is added to the commit message",
+}
+
+// Freeze command
+var Freeze = &cli.Command{
+	Name:        "freeze",
+	Usage:       "refuse deploys and releases of a service until it is unfrozen, or list freezes",
+	Description: "e.g. `shipper freeze service.foo --reason \"incident 123\"`",
+	ArgsUsage:   "[service]",
+	Flags: append(append(githubFlags(), gitopsFlags()...),
+		&cli.StringFlag{
+			Name:  "reason",
+			Usage: "why the service is frozen",
+		},
+	),
+	Action: func(c *cli.Context) error {
+		dest, proj, err := projectDestination(c)
+		if err != nil {
+			return err
+		}
+
+		svc := c.Args().First()
+
+		if svc == "" {
+			now := time.Now()
+
+			for i := range proj.Freezes {
+				f := &proj.Freezes[i]
+
+				active, err := freeze.Active(f, now)
+				if err != nil {
+					return err
+				}
+
+				if active {
+					fmt.Printf("🥶  window %s: %s\n", f.Name, f.Reason)
+				}
+			}
+
+			freezes, err := dest.GetFreezes(c.Context, proj.Name)
+			if err != nil {
+				return fmt.Errorf("failed to get freezes: %w", err)
+			}
+
+			for _, f := range freezes {
+				fmt.Printf("🥶  %s frozen by %s at %s: %s\n", f.Service, f.By, f.At.Local().Format(time.RFC1123), f.Reason)
+			}
+
+			return nil
+		}
+
+		if len(proj.Services.Lookup(svc)) == 0 {
+			return fmt.Errorf("service %s not found in project", svc)
+		}
+
+		reason := c.String("reason")
+		if reason == "" {
+			return fmt.Errorf("a --reason is required to freeze a service")
+		}
+
+		f := &destination.Freeze{
+			Service: svc,
+			Reason:  reason,
+			By:      lockHolder(),
+			At:      time.Now().UTC(),
+		}
+
+		if err := dest.Freeze(c.Context, proj.Name, f); err != nil {
+			return fmt.Errorf("failed to freeze: %w", err)
+		}
+
+		fmt.Printf("🥶  %s is frozen\n", svc)
+
+		return nil
+	},
+}
+
+// Unfreeze command
+var Unfreeze = &cli.Command{
+	Name:        "unfreeze",
+	Usage:       "lift a freeze on a service",
+	Description: "e.g. `shipper unfreeze service.foo`",
+	ArgsUsage:   "[service]",
+	Flags:       append(githubFlags(), gitopsFlags()...),
+	Action: func(c *cli.Context) error {
+		dest, proj, err := projectDestination(c)
+		if err != nil {
+			return err
+		}
+
+		svc := c.Args().First()
+		if svc == "" {
+			return fmt.Errorf("service is required")
+		}
+
+		if err := dest.Unfreeze(c.Context, proj.Name, svc); err != nil {
+			return fmt.Errorf("failed to unfreeze: %w", err)
+		}
+
+		fmt.Printf("🌤   %s is unfrozen\n", svc)
+
+		return nil
+	},
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/cygnetdigital/shipper/pkg/handler"
+	"github.com/urfave/cli/v2"
+)
+
+// Lock command
+var Lock = &cli.Command{
+	Name:  "lock",
+	Usage: "inspect the lock which serializes operations on a project",
+	Flags: []cli.Flag{},
+	Subcommands: []*cli.Command{
+		{
+			Name:        "status",
+			Usage:       "show who holds the project lock",
+			Description: "e.g. `shipper lock status`",
+			Flags:       append(githubFlags(), gitopsFlags()...),
+			Action: func(c *cli.Context) error {
+				dest, proj, err := projectDestination(c)
+				if err != nil {
+					return err
+				}
+
+				lock, err := dest.GetLock(c.Context, proj.Name)
+				if err != nil {
+					return fmt.Errorf("failed to get lock: %w", err)
+				}
+
+				if lock == nil {
+					fmt.Printf("🔓  %s is not locked\n", proj.Name)
+
+					return nil
+				}
+
+				if lock.Expired(time.Now()) {
+					fmt.Printf("⌛  %s was locked by %s for %s, expired %s\n", proj.Name, lock.Holder, lock.Operation, lock.Expires.Local().Format(time.RFC1123))
+
+					return nil
+				}
+
+				fmt.Printf("🔒  %s is locked by %s for %s until %s\n", proj.Name, lock.Holder, lock.Operation, lock.Expires.Local().Format(time.RFC1123))
+
+				return nil
+			},
+		},
+	},
+}
+
+// Unlock command
+var Unlock = &cli.Command{
+	Name:        "unlock",
+	Usage:       "release an expired project lock",
+	Description: "e.g. `shipper unlock --force`",
+	Flags: append(append(githubFlags(), gitopsFlags()...),
+		&cli.BoolFlag{
+			Name:  "force",
+			Usage: "release the lock even if it hasn't expired",
+		},
+	),
+	Action: func(c *cli.Context) error {
+		dest, proj, err := projectDestination(c)
+		if err != nil {
+			return err
+		}
+
+		if err := dest.Unlock(c.Context, proj.Name, "", c.Bool("force")); err != nil {
+			return fmt.Errorf("failed to unlock: %w", err)
+		}
+
+		fmt.Printf("🔓  %s is unlocked\n", proj.Name)
+
+		return nil
+	},
+}
+
+// lockFlags configure the lock taken by operations which push changes
+func lockFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "lock-ttl",
+			Usage: "how long the project lock is held before others may take it over",
+			Value: 15 * time.Minute,
+			EnvVars: []string{
+				"SHIPPER_LOCK_TTL",
+			},
+		},
+	}
+}
+
+// acquireLock locks the project for the operation. The returned func
+// releases the lock, warning rather than failing if that isn't possible.
+func acquireLock(c *cli.Context, hand *handler.LocalHandler, project, operation string) (func(), error) {
+	unlock, err := hand.Lock(c.Context, &handler.LockParams{
+		Project:   project,
+		Holder:    lockHolder(),
+		Operation: operation,
+		TTL:       c.Duration("lock-ttl"),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", project, err)
+	}
+
+	return func() {
+		// a fresh context, as the command's may be done by now. If shipper is
+		// killed the lock is held until its ttl expires or it's unlocked
+		if err := unlock(context.Background()); err != nil {
+			fmt.Printf("⚠️  %s, run `shipper unlock` to release it\n", err)
+		}
+	}, nil
+}
+
+// lockHolder identifies who holds a lock, e.g. alice@laptop
+func lockHolder() string {
+	name := "unknown"
+
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+
+	if host, err := os.Hostname(); err == nil {
+		name += "@" + host
+	}
+
+	return name
+}
@@ -5,8 +5,8 @@ import (
 	"os"
 
 	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/auth"
 	"github.com/cygnetdigital/shipper/internal/cliutil"
-	"github.com/cygnetdigital/shipper/internal/destination/github"
 	"github.com/cygnetdigital/shipper/internal/source"
 	"github.com/cygnetdigital/shipper/pkg/handler"
 	"github.com/urfave/cli/v2"
@@ -18,22 +18,19 @@ var Release = &cli.Command{
 	Usage:       "generate release manifests and push them to a gitops repository",
 	Description: "e.g. `shipper release service.foo`",
 	ArgsUsage:   "[service]",
-	Flags: []cli.Flag{
-		&cli.StringFlag{
-			Name: "github-token",
-			EnvVars: []string{
-				"SHIPPER_GITHUB_TOKEN",
-			},
-		},
+	Flags: append(append(append(githubFlags(), gitopsFlags()...), lockFlags()...),
 		&cli.StringFlag{
 			Name:  "version",
 			Usage: "version to release instead of the latest",
 		},
-	},
+		overrideFreezeFlag,
+		skipValidationFlag,
+		skipSecretCheckFlag,
+	),
 	Action: func(c *cli.Context) error {
-		ght := c.String("github-token")
-		if ght == "" {
-			return fmt.Errorf("github-token is required")
+		creds, err := githubCredentials(c)
+		if err != nil {
+			return err
 		}
 
 		pwd, err := os.Getwd()
@@ -46,17 +43,45 @@ var Release = &cli.Command{
 			return fmt.Errorf("failed to get project context: %w", err)
 		}
 
+		cache, err := repoCache(c)
+		if err != nil {
+			return err
+		}
+
+		dest, err := newDestination(c, proj, creds, cache)
+		if err != nil {
+			return err
+		}
+
+		src, err := source.NewGithub(proj, creds, cache)
+		if err != nil {
+			return err
+		}
+
+		// owners, policies and freeze windows come from the reviewed config, not
+		// the local copy
+		trusted, err := src.DefaultProject(c.Context, proj.Name)
+		if err != nil {
+			return fmt.Errorf("failed to load project from its default branch: %w", err)
+		}
+
 		hand := &handler.LocalHandler{
-			Source: source.NewGithub(proj, ght),
-			Dest:   github.NewGithub(proj, ght),
+			Source:    src,
+			Dest:      dest,
+			Freezes:   trusted.Freezes,
+			Approval:  trusted.Approval,
+			Identity:  auth.NewIdentity(creds),
+			Services:  trusted.Services,
+			Ownership: trusted.Ownership,
 		}
 
 		v := c.String("version")
 
 		rp := &handler.ReleaseParams{
-			Project: proj.Name,
-			Service: c.Args().First(),
-			Version: v,
+			Project:        proj.Name,
+			Service:        c.Args().First(),
+			Version:        v,
+			OverrideFreeze: c.String("override-freeze"),
 		}
 
 		rres, err := hand.Release(c.Context, rp)
@@ -74,11 +99,28 @@ var Release = &cli.Command{
 			return nil
 		}
 
+		if a := rres.Approval; a != nil {
+			if !a.Approved {
+				return fmt.Errorf("release has %d of %d approvals, ask an approver to run `shipper approve %s %s`", a.Have, a.Need, rres.Service, rres.Version)
+			}
+
+			fmt.Printf("✅  Approved (%d of %d)\n", a.Have, a.Need)
+		}
+
 		resp := cliutil.StringPrompt(fmt.Sprintf("Release %s → %s ?", rres.Service, rres.Version))
 		if resp != "YES" {
 			return fmt.Errorf("aborted: only YES is accepted")
 		}
 
+		// locked once there's something to do, the confirmed release checks
+		// the plan again while holding it
+		unlock, err := acquireLock(c, hand, proj.Name, "release")
+		if err != nil {
+			return err
+		}
+
+		defer unlock()
+
 		rp.Confirm = true
 
 		rres2, err := hand.Release(c.Context, rp)
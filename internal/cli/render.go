@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"github.com/cygnetdigital/shipper/internal/destination/gitops"
+	"github.com/cygnetdigital/shipper/internal/policy"
+	"github.com/urfave/cli/v2"
+)
+
+// Render command
+var Render = &cli.Command{
+	Name:        "render",
+	Usage:       "render the deploy bundle of a service without deploying it",
+	Description: "e.g. `shipper render --version v99 --image-tag abc1234 --templates ../gitops/templates service.foo`",
+	ArgsUsage:   "[service]",
+	Flags: append(append(append(githubFlags(), gitopsFlags()...), localDirFlags()...),
+		&cli.StringFlag{
+			Name:     "version",
+			Usage:    "version to render the deploy as, e.g. v99",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "image-tag",
+			Usage:    "image tag to deploy",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "out",
+			Usage: "dir to write the rendered files to, instead of stdout",
+		},
+	),
+	Action: func(c *cli.Context) error {
+		pwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working dir: %w", err)
+		}
+
+		proj, err := shipper.LoadProject(pwd)
+		if err != nil {
+			return fmt.Errorf("failed to get project context: %w", err)
+		}
+
+		name := c.Args().First()
+
+		svcs := proj.Services.Lookup(name)
+		if len(svcs) != 1 {
+			return fmt.Errorf("service '%s' not found in project", name)
+		}
+
+		dest, err := renderDestination(c, proj)
+		if err != nil {
+			return err
+		}
+
+		files, err := dest.RenderDeploy(c.Context, &destination.ServiceDeployParams{
+			Config:   svcs[0],
+			Version:  c.String("version"),
+			ImageTag: c.String("image-tag"),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+
+		if out := c.String("out"); out != "" {
+			if err := os.MkdirAll(out, 0755); err != nil {
+				return fmt.Errorf("failed to create %s: %w", out, err)
+			}
+
+			return destination.WriteRenderedFiles(out, files)
+		}
+
+		for _, f := range files {
+			fmt.Printf("---\n# Source: %s\n%s", f.Name, f.Data)
+		}
+
+		return nil
+	},
+}
+
+// localDirFlags are the flags to render with local templates instead of the
+// gitops repo
+func localDirFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "templates",
+			Usage: "templates dir to render with, instead of the gitops repo",
+		},
+		&cli.StringFlag{
+			Name:  "policies",
+			Usage: "policies dir to check with, instead of the gitops repo",
+		},
+		&cli.StringFlag{
+			Name:  "gitops",
+			Usage: "local checkout of the gitops repo to read templates, CRDs and policies from",
+		},
+		skipValidationFlag,
+		skipSecretCheckFlag,
+	}
+}
+
+// renderDestination sets up the destination to render with the local dirs
+// if given, which don't need access to the gitops repo. Images pinned by
+// digest get a placeholder digest rather than being looked up.
+func renderDestination(c *cli.Context, proj *shipper.Project) (*gitops.Gitops, error) {
+	dirs := &gitops.LocalDirs{
+		Templates: c.String("templates"),
+		Policies:  c.String("policies"),
+	}
+
+	if dir := c.String("gitops"); dir != "" {
+		if dirs.Templates == "" {
+			dirs.Templates = filepath.Join(dir, filepath.FromSlash(proj.Gitops.TemplatePath))
+		}
+
+		if dirs.Policies == "" {
+			dirs.Policies = filepath.Join(dir, policy.Dir)
+		}
+
+		if proj.Gitops.CRDPath != "" {
+			dirs.CRDs = filepath.Join(dir, filepath.FromSlash(proj.Gitops.CRDPath))
+		}
+
+		if p := proj.Gitops.Secrets.Path; p != "" {
+			dirs.Secrets = filepath.Join(dir, filepath.FromSlash(p))
+		}
+
+		if p := proj.Gitops.Secrets.SealedSecretsCert; p != "" {
+			dirs.SealingCert = filepath.Join(dir, filepath.FromSlash(p))
+		}
+	}
+
+	if dirs.Templates == "" {
+		dest, _, err := projectDestination(c)
+		if err != nil {
+			return nil, err
+		}
+
+		dest.SetOffline(true)
+
+		return dest, nil
+	}
+
+	dest := gitops.New(proj, nil)
+	dest.SetSkipValidation(c.Bool("skip-validation"))
+	dest.SetSkipSecretCheck(c.Bool("skip-secret-check"))
+	dest.SetAgeKeyFile(c.String("age-key-file"))
+	dest.SetChartCache(!c.Bool("no-cache"))
+	dest.SetLocalDirs(dirs)
+	dest.SetOffline(true)
+
+	return dest, nil
+}
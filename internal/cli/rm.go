@@ -5,8 +5,8 @@ import (
 	"os"
 
 	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/auth"
 	"github.com/cygnetdigital/shipper/internal/cliutil"
-	"github.com/cygnetdigital/shipper/internal/destination/github"
 	"github.com/cygnetdigital/shipper/internal/source"
 	"github.com/cygnetdigital/shipper/pkg/handler"
 	"github.com/urfave/cli/v2"
@@ -19,18 +19,11 @@ var Remove = &cli.Command{
 	Usage:       "remove a deployment from a gitops repository",
 	Description: "e.g. `shipper rm service.foo v1`",
 	ArgsUsage:   "[service] [version]",
-	Flags: []cli.Flag{
-		&cli.StringFlag{
-			Name: "github-token",
-			EnvVars: []string{
-				"SHIPPER_GITHUB_TOKEN",
-			},
-		},
-	},
+	Flags:       append(append(githubFlags(), gitopsFlags()...), lockFlags()...),
 	Action: func(c *cli.Context) error {
-		ght := c.String("github-token")
-		if ght == "" {
-			return fmt.Errorf("github-token is required")
+		creds, err := githubCredentials(c)
+		if err != nil {
+			return err
 		}
 
 		pwd, err := os.Getwd()
@@ -43,9 +36,33 @@ var Remove = &cli.Command{
 			return fmt.Errorf("failed to get project context: %w", err)
 		}
 
+		cache, err := repoCache(c)
+		if err != nil {
+			return err
+		}
+
+		dest, err := newDestination(c, proj, creds, cache)
+		if err != nil {
+			return err
+		}
+
+		src, err := source.NewGithub(proj, creds, cache)
+		if err != nil {
+			return err
+		}
+
+		// owners and policies come from the reviewed config, not the local copy
+		trusted, err := src.DefaultProject(c.Context, proj.Name)
+		if err != nil {
+			return fmt.Errorf("failed to load project from its default branch: %w", err)
+		}
+
 		hand := &handler.LocalHandler{
-			Source: source.NewGithub(proj, ght),
-			Dest:   github.NewGithub(proj, ght),
+			Source:    src,
+			Dest:      dest,
+			Identity:  auth.NewIdentity(creds),
+			Services:  trusted.Services,
+			Ownership: trusted.Ownership,
 		}
 
 		rp := &handler.RemoveParams{
@@ -64,6 +81,15 @@ var Remove = &cli.Command{
 			return fmt.Errorf("aborted: only YES is accepted")
 		}
 
+		// locked once there's something to do, the confirmed remove checks
+		// the plan again while holding it
+		unlock, err := acquireLock(c, hand, proj.Name, "remove")
+		if err != nil {
+			return err
+		}
+
+		defer unlock()
+
 		rp.Confirm = true
 
 		rres2, err := hand.Remove(c.Context, rp)
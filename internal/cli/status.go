@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/source"
+	"github.com/urfave/cli/v2"
+)
+
+// Status command
+var Status = &cli.Command{
+	Name:        "status",
+	Usage:       "show the owners and released versions of the project services",
+	Description: "e.g. `shipper status`",
+	Flags:       append(githubFlags(), gitopsFlags()...),
+	Action: func(c *cli.Context) error {
+		creds, err := githubCredentials(c)
+		if err != nil {
+			return err
+		}
+
+		pwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working dir: %w", err)
+		}
+
+		proj, err := shipper.LoadProject(pwd)
+		if err != nil {
+			return fmt.Errorf("failed to get project context: %w", err)
+		}
+
+		cache, err := repoCache(c)
+		if err != nil {
+			return err
+		}
+
+		dest, err := newDestination(c, proj, creds, cache)
+		if err != nil {
+			return err
+		}
+
+		src, err := source.NewGithub(proj, creds, cache)
+		if err != nil {
+			return err
+		}
+
+		// the owners which are enforced come from the reviewed config, not the
+		// local copy
+		trusted, err := src.DefaultProject(c.Context, proj.Name)
+		if err != nil {
+			return fmt.Errorf("failed to load project from its default branch: %w", err)
+		}
+
+		d, err := dest.Get(c.Context, proj.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get destination: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+		fmt.Fprintln(w, "SERVICE\tTIER\tOWNERS\tRELEASED\tDEPLOYS")
+
+		for _, svc := range trusted.Services {
+			released, deploys := "-", "-"
+
+			if ds := d.Services.Lookup(svc.Name); ds != nil {
+				if ds.CurrentReleaseVersion != "" {
+					released = ds.CurrentReleaseVersion
+				}
+
+				versions := []string{}
+				for _, dep := range ds.Deploys {
+					versions = append(versions, dep.Version)
+				}
+
+				if len(versions) > 0 {
+					deploys = strings.Join(versions, ",")
+				}
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", svc.Name, orDash(svc.Tier), orDash(strings.Join(svc.Owners, ",")), released, deploys)
+		}
+
+		return w.Flush()
+	},
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
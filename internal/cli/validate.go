@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/cliutil"
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"github.com/cygnetdigital/shipper/internal/policy"
+	"github.com/urfave/cli/v2"
+)
+
+// Validate command
+var Validate = &cli.Command{
+	Name:        "validate",
+	Usage:       "render the deploy bundles of services and check them against the schemas and policies",
+	Description: "e.g. `shipper validate` or `shipper validate --gitops ../gitops service.foo,service.bar`",
+	ArgsUsage:   "[services]",
+	Flags: append(append(append(githubFlags(), gitopsFlags()...), localDirFlags()...),
+		&cli.StringFlag{
+			Name:  "version",
+			Usage: "version to render the deploys as",
+			Value: "v1",
+		},
+		&cli.StringFlag{
+			Name:  "image-tag",
+			Usage: "image tag to render the deploys with",
+			Value: "validate",
+		},
+	),
+	Action: func(c *cli.Context) error {
+		pwd, err := os.Getwd()
+		if err != nil {
+			return fmt.Errorf("failed to get working dir: %w", err)
+		}
+
+		proj, err := shipper.LoadProject(pwd)
+		if err != nil {
+			return fmt.Errorf("failed to get project context: %w", err)
+		}
+
+		svcs := proj.Services
+
+		if ref := c.Args().First(); ref != "" {
+			svcs = proj.Services.Lookup(ref)
+			if len(svcs) == 0 {
+				return fmt.Errorf("no services found for '%s'", ref)
+			}
+		}
+
+		dest, err := renderDestination(c, proj)
+		if err != nil {
+			return err
+		}
+
+		p := &destination.DeployParams{ProjectName: proj.Name}
+
+		for _, svc := range svcs {
+			p.Services = append(p.Services, &destination.ServiceDeployParams{
+				Config:   svc,
+				Version:  c.String("version"),
+				ImageTag: c.String("image-tag"),
+			})
+		}
+
+		results, err := dest.CheckDeploy(c.Context, p)
+		if err != nil {
+			return err
+		}
+
+		if len(results) == 0 {
+			fmt.Printf("✅  %d services valid\n", len(svcs))
+
+			return nil
+		}
+
+		cliutil.PrintPolicyResults(os.Stdout, results)
+
+		if policy.Denied(results) {
+			return fmt.Errorf("denied by policy")
+		}
+
+		return nil
+	},
+}
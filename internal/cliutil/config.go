@@ -0,0 +1,15 @@
+package cliutil
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+)
+
+// PrintConfigChanges prints the config values changed by a deploy
+func PrintConfigChanges(w io.Writer, changes []*destination.ConfigChange) {
+	for _, c := range changes {
+		fmt.Fprintf(w, "   %s\n", c)
+	}
+}
@@ -0,0 +1,20 @@
+package cliutil
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cygnetdigital/shipper/internal/policy"
+)
+
+// PrintPolicyResults prints the policy rules which were broken
+func PrintPolicyResults(w io.Writer, results []*policy.Result) {
+	for _, r := range results {
+		icon := "⚠️"
+		if r.Severity == policy.SeverityDeny {
+			icon = "⛔"
+		}
+
+		fmt.Fprintf(w, "   %s  %s %s: %s: %s\n", icon, r.Rule, r.File, r.Object, r.Message)
+	}
+}
@@ -114,6 +114,18 @@ func (dp *DeployPrinter) printServices(w io.Writer, resp *handler.DeployResp) {
 	}
 
 	fmt.Fprintf(w, "\n")
+
+	if len(resp.Config) > 0 {
+		fmt.Fprintf(w, "🔧  Config changes:\n")
+		PrintConfigChanges(w, resp.Config)
+		fmt.Fprintf(w, "\n")
+	}
+
+	if len(resp.Policy) > 0 {
+		fmt.Fprintf(w, "📋  Policy checks:\n")
+		PrintPolicyResults(w, resp.Policy)
+		fmt.Fprintf(w, "\n")
+	}
 }
 
 func (dp *DeployPrinter) start() {
@@ -7,6 +7,17 @@ type Project struct {
 	RegistryPrefix string        `yaml:"registryPrefix"`
 	Paths          []string      `yaml:"paths"`
 	Gitops         ProjectGitops `yaml:"gitops"`
+	Build          ProjectBuild  `yaml:"build"`
+	Image          ProjectImage  `yaml:"image"`
+
+	// Freezes are windows during which deploys and releases are refused
+	Freezes []ProjectFreeze `yaml:"freezes"`
+
+	// Approval policy for releases
+	Approval ProjectApproval `yaml:"approval"`
+
+	// Ownership of services
+	Ownership ProjectOwnership `yaml:"ownership"`
 }
 
 // ProjectGitops part of config file
@@ -15,4 +26,150 @@ type ProjectGitops struct {
 	ManifestPath string `yaml:"manifestPath"`
 	TemplatePath string `yaml:"templatePath"`
 	Namespace    string `yaml:"namespace"`
+
+	// CRDPath is a dir in the gitops repo with the CRDs of custom resources
+	// the templates use, to validate them against
+	CRDPath string `yaml:"crdPath"`
+
+	// Secrets configures how service secrets are rendered and checked
+	Secrets ProjectSecrets `yaml:"secrets"`
+
+	// Output is the layout of the manifests. Either "plain" (default) or
+	// "kustomize" to also write kustomizations for each bundle, service and
+	// the manifest path.
+	Output string `yaml:"output"`
+
+	// Branch to deploy to. Defaults to the default branch of the repo
+	Branch string `yaml:"branch"`
+
+	// Mode is how a github gitops repo is accessed. Either "clone" (default)
+	// or "api" to use the github git data API without cloning.
+	Mode string `yaml:"mode"`
+
+	// CommitAuthor of gitops commits. Defaults to the github user.
+	CommitAuthor CommitAuthor `yaml:"commitAuthor"`
+
+	// Signing of gitops commits
+	Signing CommitSigning `yaml:"signing"`
+
+	// CommitMessage is a go template for commit messages, rendered with
+	// .Summary, .Body, .Operation, .Project, .Services, .PullRequest,
+	// .CoAuthors (each with a .Login and .Email) and .Owners
+	CommitMessage string `yaml:"commitMessage"`
+}
+
+// ProjectSecrets part of config file
+type ProjectSecrets struct {
+	// Path is a dir in the gitops repo with the secrets services reference,
+	// to check they exist
+	Path string `yaml:"path"`
+
+	// SealedSecretsCert is the path in the gitops repo of the sealed secrets
+	// controller's cert, to seal secrets with
+	SealedSecretsCert string `yaml:"sealedSecretsCert"`
+
+	// Store is the external secrets store to read external secrets from
+	Store string `yaml:"store"`
+
+	// StoreKind is SecretStore (default) or ClusterSecretStore
+	StoreKind string `yaml:"storeKind"`
+}
+
+// CommitAuthor part of config file
+type CommitAuthor struct {
+	Name  string `yaml:"name"`
+	Email string `yaml:"email"`
+}
+
+// CommitSigning part of config file
+type CommitSigning struct {
+	// Format of the key, either "gpg" or "ssh". Commits are unsigned if empty.
+	Format string `yaml:"format"`
+
+	// Key is the path of the private key file. Its passphrase is read from
+	// SHIPPER_GITOPS_SIGNING_PASSPHRASE.
+	Key string `yaml:"key"`
+}
+
+// ProjectBuild part of config file
+type ProjectBuild struct {
+	// Provider of build statuses. Either "actions" (default) to use Github
+	// Actions jobs, "statuses" to use commit statuses reported by other CI, or
+	// "registry" to check the image has been pushed to the registry.
+	Provider string `yaml:"provider"`
+
+	// StatusContextPattern is a regexp used to map a commit status context to
+	// a service name. The first capture group is used if there is one.
+	StatusContextPattern string `yaml:"statusContextPattern"`
+}
+
+// ProjectImage part of config file
+type ProjectImage struct {
+	// PinDigest resolves the image tag to a digest at deploy time, so the
+	// manifests reference an immutable image.
+	PinDigest bool `yaml:"pinDigest"`
+
+	// TagTemplate is a go template rendered against the resolved source ref
+	// to produce the image tag. Defaults to the short commit hash.
+	TagTemplate string `yaml:"tagTemplate"`
+}
+
+// ProjectFreeze is a window during which deploys and releases are refused.
+// It is either a date range (start and end), or a cron schedule which opens
+// a window lasting duration each time it fires.
+type ProjectFreeze struct {
+	Name   string `yaml:"name"`
+	Reason string `yaml:"reason"`
+
+	// Start and End of a date range, as 2006-01-02 or RFC3339. A date on its
+	// own for End includes the whole day.
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+
+	// Cron is a five field schedule (minute hour day month weekday), e.g.
+	// "0 16 * * 5" for friday afternoons with a duration of 64h
+	Cron     string `yaml:"cron"`
+	Duration string `yaml:"duration"`
+
+	// Timezone the dates and schedule are in. Defaults to UTC.
+	Timezone string `yaml:"timezone"`
+
+	// Services the freeze applies to. Defaults to all services.
+	Services []string `yaml:"services"`
+}
+
+// ProjectApproval part of config file
+type ProjectApproval struct {
+	// Default rule for all services
+	ApprovalRule `yaml:",inline"`
+
+	// Services with their own rule, replacing the default
+	Services map[string]ApprovalRule `yaml:"services"`
+}
+
+// ApprovalRule describes who must approve a release before it goes out
+type ApprovalRule struct {
+	// Required number of approvals. Defaults to 1 if approvers or teams are
+	// set, otherwise no approval is needed.
+	Required int `yaml:"required"`
+
+	// Approvers are the github logins allowed to approve. Anyone may approve
+	// if neither approvers nor teams are set.
+	Approvers []string `yaml:"approvers"`
+
+	// Teams whose members may approve, as org/team-slug
+	Teams []string `yaml:"teams"`
+
+	// AllowSelfApproval lets the author of a release approve it
+	AllowSelfApproval bool `yaml:"allowSelfApproval"`
+}
+
+// ProjectOwnership part of config file
+type ProjectOwnership struct {
+	// Enforce that only service owners can release or remove their services
+	Enforce bool `yaml:"enforce"`
+
+	// BreakGlass are github logins or org/team-slug teams who may release or
+	// remove any service when ownership is enforced
+	BreakGlass []string `yaml:"breakGlass"`
 }
@@ -5,11 +5,27 @@ type Service struct {
 	Name   string        `yaml:"name"`
 	Build  ServiceBuild  `yaml:"build"`
 	Deploy ServiceDeploy `yaml:"deploy"`
+
+	// Owners of the service, as github logins or org/team-slug teams
+	Owners []string `yaml:"owners"`
+
+	// Tier labels how critical the service is, e.g. tier-1
+	Tier string `yaml:"tier"`
 }
 
 // ServiceBuild part of service file config
 type ServiceBuild struct {
-	Dockerfile string `yaml:"dockerfile"`
+	Dockerfile string       `yaml:"dockerfile"`
+	Image      ServiceImage `yaml:"image"`
+}
+
+// ServiceImage part of service file config
+type ServiceImage struct {
+	// Name of the image under the registry prefix. Defaults to the service name
+	Name string `yaml:"name"`
+
+	// TagTemplate overrides the project image tag template for this service
+	TagTemplate string `yaml:"tagTemplate"`
 }
 
 // ServiceDeploy part of service file config
@@ -17,11 +33,45 @@ type ServiceDeploy struct {
 	// Template is the name of the template to use for the deployment
 	Template string `yaml:"template"`
 
-	// SecretMounts are used to mount secrets into the container
+	// SecretMounts are used to mount secrets into the container. The secret
+	// name is either a secret in the gitops repo, or one of the service's
+	// secrets.
 	SecretMounts []*SecretMount `yaml:"secretMounts"`
 
 	// Config is used to setup environment variables into the container
 	Config []*ServiceConfigItem `yaml:"config"`
+
+	// ConfigMode is how config is given to the deploy. Either "env" (default)
+	// for SHIPPER_<NAME> variables of json, "configmap" for a config map of
+	// SHIPPER_<NAME>_<KEY> keys, or "files" for a config map of <name>.json
+	// files to mount. Config maps are versioned with the deploy.
+	ConfigMode string `yaml:"configMode"`
+
+	// Secrets rendered with the deploy, from encrypted values or an external
+	// secret store
+	Secrets []*ServiceSecret `yaml:"secrets"`
+
+	// Params are passed to the template, as declared in its template.yaml.
+	// Charts get them as values.
+	Params map[string]any `yaml:"params"`
+
+	// Chart options, when the template is a helm chart
+	Chart ServiceChart `yaml:"chart"`
+}
+
+// ServiceChart configures a deploy template which is a helm chart, either
+// vendored in the templates dir with a Chart.yaml, or an oci:// chart url
+type ServiceChart struct {
+	// Version of an oci chart
+	Version string `yaml:"version"`
+
+	// Output is "manifests" (default) to render the chart, "application" for
+	// an Argo CD Application or "helmrelease" for a Flux HelmRelease
+	Output string `yaml:"output"`
+
+	// Namespace of the Application or HelmRelease. Defaults to argocd for
+	// applications, and the project namespace for helm releases.
+	Namespace string `yaml:"namespace"`
 }
 
 // ServiceConfigItem is a single config item
@@ -31,6 +81,39 @@ type ServiceConfigItem struct {
 
 	// Hard coded values for this config
 	Values map[string]string `yaml:"values"`
+
+	// ValueFrom sets config from kubernetes secrets, as SHIPPER_<NAME>_<KEY>
+	// environment variables
+	ValueFrom map[string]*ConfigValueSource `yaml:"valueFrom"`
+}
+
+// ConfigValueSource is where a config value comes from
+type ConfigValueSource struct {
+	SecretKeyRef *SecretKeyRef `yaml:"secretKeyRef"`
+}
+
+// SecretKeyRef is a key of a kubernetes secret. The name is either a secret
+// in the gitops repo, or one of the service's secrets.
+type SecretKeyRef struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+}
+
+// ServiceSecret is a kubernetes secret rendered with each deploy, named
+// <slug>-<version>-<name>. Exactly one of sealed or external is set.
+type ServiceSecret struct {
+	Name string `yaml:"name"`
+
+	// Sealed values are age encrypted (age -a), or encrypted in place by
+	// sops with its age backend, e.g.
+	//   sops -e -i --age <recipient> --encrypted-regex '^sealed$' shipper.yaml
+	// They're decrypted and sealed into a SealedSecret with the project's
+	// sealed secrets cert on deploy.
+	Sealed map[string]string `yaml:"sealed"`
+
+	// External values are keys in the project's external secret store,
+	// optionally with a #property, rendered as an ExternalSecret
+	External map[string]string `yaml:"external"`
 }
 
 // SecretMount is a single secret mount
@@ -0,0 +1,141 @@
+package destination
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// attribute the objects in the yaml documents to a bundle by setting the
+// annotations, failing if an object is already annotated with other values
+func attribute(bts []byte, annotations map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return editObjects(bts, func(anns *yaml.Node) error {
+		for _, k := range keys {
+			if v := lookupMappingValue(anns, k); v != nil && v.Value != annotations[k] {
+				return fmt.Errorf("annotation %s is '%s', expected '%s'", k, v.Value, annotations[k])
+			}
+
+			setMappingValue(anns, k, annotations[k])
+		}
+
+		return nil
+	})
+}
+
+// editObjects calls fn with the annotations of every object in the yaml
+// documents, and encodes them again
+func editObjects(bts []byte, fn func(anns *yaml.Node) error) ([]byte, error) {
+	docs := []*yaml.Node{}
+	decoder := yaml.NewDecoder(bytes.NewReader(bts))
+
+	for i := 0; ; i++ {
+		doc := &yaml.Node{}
+
+		if err := decoder.Decode(doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, err
+		}
+
+		// skip empty documents, and ones with only comments as charts render
+		if len(doc.Content) == 0 || doc.Content[0].Tag == "!!null" {
+			continue
+		}
+
+		if doc.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("document %d is not an object", i)
+		}
+
+		meta := mappingValue(doc.Content[0], "metadata")
+		anns := mappingValue(meta, "annotations")
+
+		if err := fn(anns); err != nil {
+			return nil, fmt.Errorf("document %d: %w", i, err)
+		}
+
+		docs = append(docs, doc)
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// lookupMappingValue returns the value of key, or nil if it is missing
+func lookupMappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// mappingValue returns the mapping under key, creating it if it is missing
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			v := node.Content[i+1]
+
+			// replace explicit nulls (e.g. `annotations:`) with a mapping
+			if v.Kind != yaml.MappingNode {
+				*v = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			}
+
+			return v
+		}
+	}
+
+	v := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		v,
+	)
+
+	return v
+}
+
+// setMappingValue sets key to a string value, replacing any existing value
+func setMappingValue(node *yaml.Node, key, value string) {
+	v := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = v
+
+			return
+		}
+	}
+
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		v,
+	)
+}
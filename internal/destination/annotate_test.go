@@ -0,0 +1,134 @@
+package destination
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAttribute(t *testing.T) {
+	annotations := map[string]string{
+		"shipper/service": "api",
+		"shipper/version": "v2",
+	}
+
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "no metadata",
+			in:   "kind: ConfigMap\n",
+			want: `kind: ConfigMap
+metadata:
+  annotations:
+    shipper/service: api
+    shipper/version: v2
+`,
+		},
+		{
+			name: "other annotations are kept",
+			in: `kind: Service
+metadata:
+  name: api
+  annotations:
+    team: core
+`,
+			want: `kind: Service
+metadata:
+  name: api
+  annotations:
+    team: core
+    shipper/service: api
+    shipper/version: v2
+`,
+		},
+		{
+			name: "null annotations",
+			in: `kind: Service
+metadata:
+  name: api
+  annotations:
+`,
+			want: `kind: Service
+metadata:
+  name: api
+  annotations:
+    shipper/service: api
+    shipper/version: v2
+`,
+		},
+		{
+			name: "already attributed",
+			in: `kind: Service
+metadata:
+  annotations:
+    shipper/version: v2
+`,
+			want: `kind: Service
+metadata:
+  annotations:
+    shipper/version: v2
+    shipper/service: api
+`,
+		},
+		{
+			name: "empty and comment only documents are skipped",
+			in: `---
+# Source: chart/templates/disabled.yaml
+---
+kind: ConfigMap
+---
+`,
+			want: `kind: ConfigMap
+metadata:
+  annotations:
+    shipper/service: api
+    shipper/version: v2
+`,
+		},
+		{
+			name: "annotated for another version",
+			in: `kind: ConfigMap
+---
+kind: Service
+metadata:
+  annotations:
+    shipper/version: v1
+`,
+			wantErr: "document 1: annotation shipper/version is 'v1', expected 'v2'",
+		},
+		{
+			name:    "not an object",
+			in:      "- kind: Service\n",
+			wantErr: "document 0 is not an object",
+		},
+		{
+			name:    "invalid yaml",
+			in:      "kind: [Service\n",
+			wantErr: "did not find expected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := attribute([]byte(tt.in), annotations)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %s", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("attribute: %v", err)
+			}
+
+			if string(got) != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package destination
+
+import (
+	"fmt"
+	"time"
+)
+
+// ApprovalDir is where approval records are kept in the destination
+const ApprovalDir = ".shipper/approvals"
+
+// Approval records the approvals given to an operation
+type Approval struct {
+	// ID of the plan being approved
+	ID        string `yaml:"id"`
+	Operation string `yaml:"operation"`
+	Project   string `yaml:"project"`
+	Service   string `yaml:"service"`
+	Version   string `yaml:"version"`
+
+	// From is the version being replaced
+	From string `yaml:"from"`
+
+	Approvals []*ApprovalSignoff `yaml:"approvals"`
+}
+
+// ApprovalSignoff is a single approval, signed by the approver
+type ApprovalSignoff struct {
+	By string    `yaml:"by"`
+	At time.Time `yaml:"at"`
+
+	// Signature is an armored ssh or gpg signature of the signoff's payload
+	Signature string `yaml:"signature"`
+}
+
+// SignoffPayload is what an approver signs to approve the plan
+func (a *Approval) SignoffPayload(s *ApprovalSignoff) []byte {
+	return []byte(fmt.Sprintf("shipper approval\nid %s\noperation %s\nproject %s\nservice %s\nversion %s\nfrom %s\nby %s\nat %s\n",
+		a.ID, a.Operation, a.Project, a.Service, a.Version, a.From, s.By, s.At.UTC().Format(time.RFC3339)))
+}
+
+// Matches returns true if the approval is for the same operation as the
+// plan, regardless of its signoffs
+func (a *Approval) Matches(plan *Approval) bool {
+	return a.ID == plan.ID && a.Operation == plan.Operation && a.Project == plan.Project &&
+		a.Service == plan.Service && a.Version == plan.Version && a.From == plan.From
+}
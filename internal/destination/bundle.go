@@ -1,9 +1,11 @@
 package destination
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"text/template"
 
@@ -27,36 +29,120 @@ type DeployContext struct {
 	// slugified name of service with version. e.g. s-foo-v1
 	SlugNameVersion string
 
-	// Deployment image of service. e.g. gcr.io/foo/service:v1.0.0
+	// Deployment image of service. e.g. gcr.io/foo/service:v1.0.0, or
+	// gcr.io/foo/service@sha256:... when pinned by digest
 	DeployImage string
 
-	// DeployVariables are environment variables to pass to the deployment
+	// ImageDigest is the digest the image tag resolved to, if pinned
+	ImageDigest string
+
+	// DeployVariables are environment variables to pass to the deployment.
+	// Empty when config is rendered as a config map.
 	DeployVariables map[string]string
 
+	// ConfigMap holding the config of this deploy. e.g. s-foo-v1-config, or
+	// empty when config is passed as environment variables
+	ConfigMap string
+
 	// SecretMounts are used to mount secrets into the container
 	SecretMounts []*conf.SecretMount
 
+	// SecretVariables are environment variables set from kubernetes secrets
+	SecretVariables []*SecretVariable
+
+	// Secrets rendered with this deploy, by their name in the service config.
+	// e.g. db: s-foo-v1-db
+	Secrets map[string]string
+
 	// Namespace to put deployment in. e.g. default
 	Namespace string
+
+	// Params given by the service, with defaults from the template spec
+	Params map[string]any
+}
+
+// RenderedFile is a manifest file rendered from a template
+type RenderedFile struct {
+	Name string
+	Data []byte
+
+	// Generated files hold custom resources written by shipper rather than
+	// rendered from the project's templates, so they aren't validated
+	// against the schemas, as their CRDs are rarely in the gitops repo
+	Generated bool
+}
+
+// RenderDeployBundle renders the deploy template without writing it out.
+// Every object is annotated with the service and version it belongs to, so it
+// is found by LoadServices.
+func RenderDeployBundle(templatesDir, template string, args *DeployContext) ([]*RenderedFile, error) {
+	templateDir := path.Join(templatesDir, "deploy", template)
+	sharedDir := path.Join(templatesDir, sharedTemplateDir)
+
+	files, err := renderTemplates(sharedDir, templateDir, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := AttributeDeployBundle(args, files); err != nil {
+		return nil, err
+	}
+
+	return files, nil
 }
 
-// WriteDeployBundle ...
-func WriteDeployBundle(templatesDir, manifestRoot string, template string, args *DeployContext) error {
+// AttributeDeployBundle annotates every object in the rendered files with
+// the service and version they belong to
+func AttributeDeployBundle(args *DeployContext, files []*RenderedFile) error {
+	annotations := map[string]string{
+		"shipper/bundle":          "deploy",
+		"shipper/project":         args.Project,
+		"shipper/service-name":    args.Name,
+		"shipper/service-version": args.Version,
+	}
+
+	if args.ImageDigest != "" {
+		annotations["shipper/image-digest"] = args.ImageDigest
+	}
+
+	for _, f := range files {
+		data, err := attribute(f.Data, annotations)
+		if err != nil {
+			return fmt.Errorf("failed to attribute %s to %s/%s: %w", f.Name, args.Name, args.Version, err)
+		}
+
+		f.Data = data
+	}
+
+	return nil
+}
+
+// WriteDeployBundle writes the rendered deploy bundle to the manifests
+func WriteDeployBundle(manifestRoot string, args *DeployContext, files []*RenderedFile) error {
 	deployDir := path.Join(manifestRoot, args.Name, args.Version)
 
 	if err := ensureDir(deployDir); err != nil {
 		return fmt.Errorf("failed to ensure deploy dir exists '%s': %w", deployDir, err)
 	}
 
-	templateDir := path.Join(templatesDir, "deploy", template)
-
-	if err := writeTemplateOut(templateDir, deployDir, args); err != nil {
+	if err := WriteRenderedFiles(deployDir, files); err != nil {
 		return fmt.Errorf("failed to write deploy bundle: %w", err)
 	}
 
 	return nil
 }
 
+// WriteRenderedFiles to the directory
+func WriteRenderedFiles(dir string, files []*RenderedFile) error {
+	for _, f := range files {
+		if err := os.WriteFile(path.Join(dir, f.Name), f.Data, 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
 // DeleteDeployBundle ...
 func DeleteDeployBundle(manifestRoot string, serviceName, version string) error {
 	deployDir := path.Join(manifestRoot, serviceName, version)
@@ -104,65 +190,118 @@ spec:
   ports:
     - name: http
       port: 80
-			targetPort: 8000
+      targetPort: 8000
   selector:
     app: {{ .SlugName }}
     version: {{ .Version }}
 `
 
-// WriteReleaseBundle ...
-func WriteReleaseBundle(manifestRoot string, args *ReleaseContext) error {
-	t, err := template.New("service.yaml").Parse(strings.TrimSpace(releaseTemplate))
+// RenderReleaseBundle renders the release bundle without writing it out
+func RenderReleaseBundle(args *ReleaseContext) ([]*RenderedFile, error) {
+	t := template.New("service.yaml")
+
+	t, err := t.Funcs(templateFuncs(t)).Parse(strings.TrimSpace(releaseTemplate))
 	if err != nil {
-		return fmt.Errorf("failed to parse template: %w", err)
+		return nil, fmt.Errorf("failed to parse template: %w", err)
 	}
 
+	buf := &bytes.Buffer{}
+
+	if err := t.Execute(buf, args); err != nil {
+		return nil, fmt.Errorf("failed to execute template %s: %w", t.Name(), err)
+	}
+
+	return []*RenderedFile{{Name: t.Name(), Data: buf.Bytes()}}, nil
+}
+
+// WriteReleaseBundle writes the rendered release bundle to the manifests
+func WriteReleaseBundle(manifestRoot string, args *ReleaseContext, files []*RenderedFile) error {
 	releaseDir := path.Join(manifestRoot, args.Name)
 
-	if err := writeTemplate(t, releaseDir, args); err != nil {
+	if err := WriteRenderedFiles(releaseDir, files); err != nil {
 		return fmt.Errorf("failed to write release bundle: %w", err)
 	}
 
 	return nil
 }
 
-// writeTemplateOut to files in the destination directory
-func writeTemplateOut(templateDir, destDir string, arg any) error {
-	templateFiles := fmt.Sprintf("%s/*.yaml", templateDir)
+// sharedTemplateDir holds partials available to every template, relative to
+// the templates path
+const sharedTemplateDir = "_shared"
 
-	t, err := template.New("").ParseGlob(templateFiles)
+// renderTemplates in the template dir. Partials (*.tpl) from the shared dir
+// and files starting with _ in the template dir can be used with include, but
+// aren't rendered out.
+func renderTemplates(sharedDir, templateDir string, arg any) ([]*RenderedFile, error) {
+	t, outputs, err := parseTemplates(sharedDir, templateDir)
 	if err != nil {
-		return fmt.Errorf("failed to parse templates %s: %w", templateFiles, err)
+		return nil, err
 	}
 
-	tpls := t.Templates()
+	files := []*RenderedFile{}
 
-	if len(tpls) == 0 {
-		return fmt.Errorf("no templates found %s", templateFiles)
-	}
+	for _, name := range outputs {
+		buf := &bytes.Buffer{}
 
-	for _, tp := range tpls {
-		if tp.Name() == "" {
-			continue
+		if err := t.ExecuteTemplate(buf, name, arg); err != nil {
+			return nil, fmt.Errorf("failed to execute template %s: %w", name, err)
 		}
 
-		if err := writeTemplate(tp, destDir, arg); err != nil {
-			return fmt.Errorf("failed to write template %s: %w", tp.Name(), err)
-		}
+		files = append(files, &RenderedFile{Name: name, Data: buf.Bytes()})
 	}
 
-	return nil
+	return files, nil
 }
 
-func writeTemplate(tp *template.Template, destDir string, arg any) error {
-	f, err := os.Create(path.Join(destDir, tp.Name()))
+// parseTemplates parses the partials and templates into one set, returning
+// the names of the templates which should be written out
+func parseTemplates(sharedDir, templateDir string) (*template.Template, []string, error) {
+	templateFiles := fmt.Sprintf("%s/*.yaml", templateDir)
+
+	matches, err := filepath.Glob(templateFiles)
 	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", tp.Name(), err)
+		return nil, nil, err
 	}
 
-	if err := tp.Execute(f, arg); err != nil {
-		return fmt.Errorf("failed to execute template %s: %w", tp.Name(), err)
+	files := []string{}
+
+	for _, f := range matches {
+		if filepath.Base(f) != templateSpecFile {
+			files = append(files, f)
+		}
+	}
+
+	partials, err := filepath.Glob(path.Join(sharedDir, "*.tpl"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	local, err := filepath.Glob(path.Join(templateDir, "_*.tpl"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// local partials are parsed last so they override shared ones
+	partials = append(partials, local...)
+
+	outputs := []string{}
+
+	for _, f := range files {
+		if name := filepath.Base(f); !strings.HasPrefix(name, "_") {
+			outputs = append(outputs, name)
+		}
+	}
+
+	if len(outputs) == 0 {
+		return nil, nil, fmt.Errorf("no templates found %s", templateFiles)
+	}
+
+	t := template.New("")
+	t.Funcs(templateFuncs(t))
+
+	if _, err := t.ParseFiles(append(partials, files...)...); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse templates %s: %w", templateFiles, err)
 	}
 
-	return f.Close()
+	return t, outputs, nil
 }
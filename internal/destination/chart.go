@@ -0,0 +1,199 @@
+package destination
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Chart outputs
+const (
+	ChartManifests   = "manifests"
+	ChartApplication = "application"
+	ChartHelmRelease = "helmrelease"
+)
+
+// chartFile marks a template dir as a vendored helm chart
+const chartFile = "Chart.yaml"
+
+// chartInterval is how often flux reconciles helm releases
+const chartInterval = "10m"
+
+// IsChart returns true if the template dir is a vendored helm chart
+func IsChart(templateDir string) bool {
+	_, err := os.Stat(path.Join(templateDir, chartFile))
+
+	return err == nil
+}
+
+// ChartSource is where a gitops controller gets a chart from
+type ChartSource struct {
+	// RepoURL of the oci registry, with the oci:// scheme, or of the git repo
+	RepoURL string
+
+	// Chart name in the oci registry
+	Chart string
+
+	// Path of the chart in the git repo
+	Path string
+
+	// Version of the chart, or revision of the git repo
+	Version string
+}
+
+// ChartValues are the values a chart is rendered with: the service params,
+// and the deploy context under shipper
+func ChartValues(args *DeployContext) map[string]any {
+	values := map[string]any{}
+
+	for k, v := range args.Params {
+		values[k] = v
+	}
+
+	values["shipper"] = map[string]any{
+		"project":         args.Project,
+		"name":            args.Name,
+		"slugName":        args.SlugName,
+		"version":         args.Version,
+		"slugNameVersion": args.SlugNameVersion,
+		"image":           args.DeployImage,
+		"imageDigest":     args.ImageDigest,
+		"namespace":       args.Namespace,
+		"env":             args.DeployVariables,
+		"configMap":       args.ConfigMap,
+		"secrets":         args.Secrets,
+	}
+
+	return values
+}
+
+// RenderChartApplication renders an Argo CD Application installing the chart
+// into the deploy namespace. The Application itself is put in namespace.
+func RenderChartApplication(args *DeployContext, src *ChartSource, namespace string) ([]*RenderedFile, error) {
+	values, err := encodeYAML(ChartValues(args))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode values: %w", err)
+	}
+
+	source := map[string]any{
+		"repoURL":        src.RepoURL,
+		"targetRevision": src.Version,
+		"helm": map[string]any{
+			"releaseName": args.SlugNameVersion,
+			"values":      string(values),
+		},
+	}
+
+	if src.Chart != "" {
+		source["repoURL"] = strings.TrimPrefix(src.RepoURL, "oci://")
+		source["chart"] = src.Chart
+	} else {
+		source["path"] = src.Path
+	}
+
+	app := map[string]any{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Application",
+		"metadata": map[string]any{
+			"name":      args.SlugNameVersion,
+			"namespace": namespace,
+		},
+		"spec": map[string]any{
+			"project": "default",
+			"source":  source,
+			"destination": map[string]any{
+				"server":    "https://kubernetes.default.svc",
+				"namespace": args.Namespace,
+			},
+			"syncPolicy": map[string]any{
+				"automated": map[string]any{"prune": true},
+			},
+		},
+	}
+
+	data, err := encodeYAML(app)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*RenderedFile{{Name: "application.yaml", Data: data, Generated: true}}, nil
+}
+
+// RenderChartHelmRelease renders a Flux HelmRelease, and the HelmRepository
+// it pulls the chart from, installing the chart into the deploy namespace.
+// Only oci charts can be released this way.
+func RenderChartHelmRelease(args *DeployContext, src *ChartSource, namespace string) ([]*RenderedFile, error) {
+	if src.Chart == "" {
+		return nil, fmt.Errorf("helm releases need an oci:// chart")
+	}
+
+	repo := map[string]any{
+		"apiVersion": "source.toolkit.fluxcd.io/v1beta2",
+		"kind":       "HelmRepository",
+		"metadata": map[string]any{
+			"name":      args.SlugNameVersion,
+			"namespace": namespace,
+		},
+		"spec": map[string]any{
+			"type":     "oci",
+			"url":      src.RepoURL,
+			"interval": chartInterval,
+		},
+	}
+
+	release := map[string]any{
+		"apiVersion": "helm.toolkit.fluxcd.io/v2beta1",
+		"kind":       "HelmRelease",
+		"metadata": map[string]any{
+			"name":      args.SlugNameVersion,
+			"namespace": namespace,
+		},
+		"spec": map[string]any{
+			"interval":        chartInterval,
+			"releaseName":     args.SlugNameVersion,
+			"targetNamespace": args.Namespace,
+			"chart": map[string]any{
+				"spec": map[string]any{
+					"chart":   src.Chart,
+					"version": src.Version,
+					"sourceRef": map[string]any{
+						"kind": "HelmRepository",
+						"name": args.SlugNameVersion,
+					},
+				},
+			},
+			"values": ChartValues(args),
+		},
+	}
+
+	data, err := encodeYAML(repo, release)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*RenderedFile{{Name: "helmrelease.yaml", Data: data, Generated: true}}, nil
+}
+
+// encodeYAML encodes each value as a yaml document
+func encodeYAML(docs ...any) ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+
+	for _, d := range docs {
+		if err := enc.Encode(d); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
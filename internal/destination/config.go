@@ -0,0 +1,210 @@
+package destination
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cygnetdigital/shipper/internal/conf"
+)
+
+// Config modes
+const (
+	ConfigEnv       = "env"
+	ConfigConfigMap = "configmap"
+	ConfigFiles     = "files"
+)
+
+// configMapFile is the file in the deploy bundle holding its config map
+const configMapFile = "config.yaml"
+
+// ConfigMapName is the name of the config map of a deploy. e.g. s-foo-v1-config
+func ConfigMapName(slugNameVersion string) string {
+	return slugNameVersion + "-config"
+}
+
+var invalidKeyChars = regexp.MustCompile(`[^A-Z0-9_]+`)
+
+// ConfigKey is the SHIPPER_<NAME>_<KEY> variable of a config value
+func ConfigKey(name, key string) string {
+	return invalidKeyChars.ReplaceAllString(strings.ToUpper(fmt.Sprintf("SHIPPER_%s_%s", name, key)), "_")
+}
+
+// ConfigData is the data of the config map for the config items. In the
+// configmap mode every value is a key named SHIPPER_<NAME>_<KEY>, and in the
+// files mode every item is a <name>.json file.
+func ConfigData(items []*conf.ServiceConfigItem, mode string) (map[string]string, error) {
+	data := map[string]string{}
+
+	for _, c := range items {
+		switch mode {
+		case ConfigConfigMap:
+			for k, v := range c.Values {
+				key := ConfigKey(c.Name, k)
+
+				if _, ok := data[key]; ok {
+					return nil, fmt.Errorf("config key %s is set more than once", key)
+				}
+
+				data[key] = v
+			}
+		case ConfigFiles:
+			bts, err := json.Marshal(c.Values)
+			if err != nil {
+				return nil, err
+			}
+
+			key := c.Name + ".json"
+
+			if _, ok := data[key]; ok {
+				return nil, fmt.Errorf("config %s is set more than once", c.Name)
+			}
+
+			data[key] = string(bts)
+		default:
+			return nil, fmt.Errorf("unknown config mode '%s'", mode)
+		}
+	}
+
+	return data, nil
+}
+
+// RenderConfigMap renders the config map of the deploy with the data
+func RenderConfigMap(args *DeployContext, data map[string]string) (*RenderedFile, error) {
+	cm := struct {
+		APIVersion string            `yaml:"apiVersion"`
+		Kind       string            `yaml:"kind"`
+		Metadata   map[string]string `yaml:"metadata"`
+		Data       map[string]string `yaml:"data"`
+	}{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Metadata:   map[string]string{"name": args.ConfigMap, "namespace": args.Namespace},
+		Data:       data,
+	}
+
+	bts, err := encodeYAML(cm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config map: %w", err)
+	}
+
+	return &RenderedFile{Name: configMapFile, Data: bts}, nil
+}
+
+// AddConfigMap renders the config map of the deploy into the bundle, if its
+// config is in one
+func AddConfigMap(args *DeployContext, items []*conf.ServiceConfigItem, mode string, files []*RenderedFile) ([]*RenderedFile, error) {
+	if args.ConfigMap == "" {
+		return files, nil
+	}
+
+	for _, f := range files {
+		if f.Name == configMapFile {
+			return nil, fmt.Errorf("the template can't have a %s, it holds the config map", configMapFile)
+		}
+	}
+
+	data, err := ConfigData(items, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	cm, err := RenderConfigMap(args, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := AttributeDeployBundle(args, []*RenderedFile{cm}); err != nil {
+		return nil, err
+	}
+
+	return append(files, cm), nil
+}
+
+// ConfigData returns the data of the deploy's config map, or nil if its
+// config isn't in one
+func (d *Deploy) ConfigData() (map[string]string, error) {
+	slug, err := SlugifyServiceName(d.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	name := ConfigMapName(fmt.Sprintf("%s-%s", slug, d.Version))
+
+	for _, m := range d.Manifests {
+		if m.Kind != "ConfigMap" || m.Name != name {
+			continue
+		}
+
+		cm := struct {
+			Data map[string]string `json:"data"`
+		}{}
+
+		if err := json.Unmarshal(m.Raw, &cm); err != nil {
+			return nil, fmt.Errorf("failed to decode config map %s: %w", name, err)
+		}
+
+		return cm.Data, nil
+	}
+
+	return nil, nil
+}
+
+// ConfigChange is a config value changed by a deploy
+type ConfigChange struct {
+	Service string
+	Key     string
+	Old     string
+	New     string
+	Added   bool
+	Removed bool
+}
+
+func (c *ConfigChange) String() string {
+	switch {
+	case c.Added:
+		return fmt.Sprintf("+ %s %s: %s", c.Service, c.Key, c.New)
+	case c.Removed:
+		return fmt.Sprintf("- %s %s: %s", c.Service, c.Key, c.Old)
+	default:
+		return fmt.Sprintf("~ %s %s: %s → %s", c.Service, c.Key, c.Old, c.New)
+	}
+}
+
+// DiffConfig between the config map data of the last deploy of the service
+// and the next
+func DiffConfig(service string, last, next map[string]string) []*ConfigChange {
+	keys := []string{}
+
+	for k := range last {
+		keys = append(keys, k)
+	}
+
+	for k := range next {
+		if _, ok := last[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	changes := []*ConfigChange{}
+
+	for _, k := range keys {
+		o, hadOld := last[k]
+		n, hasNew := next[k]
+
+		switch {
+		case !hadOld:
+			changes = append(changes, &ConfigChange{Service: service, Key: k, New: n, Added: true})
+		case !hasNew:
+			changes = append(changes, &ConfigChange{Service: service, Key: k, Old: o, Removed: true})
+		case o != n:
+			changes = append(changes, &ConfigChange{Service: service, Key: k, Old: o, New: n})
+		}
+	}
+
+	return changes
+}
@@ -1,6 +1,9 @@
 package destination
 
-import "github.com/cygnetdigital/shipper"
+import (
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/policy"
+)
 
 // Destination encapsulates the current state of a destination
 type Destination struct {
@@ -22,6 +25,26 @@ func (d *Destination) NextDeployVersion(project, service string) (string, error)
 type DeployParams struct {
 	ProjectName string
 	Services    []*ServiceDeployParams
+
+	// FreezeOverride is the reason given for deploying during a freeze
+	FreezeOverride string
+
+	// PullRequest url and the people involved in the change, for the commit
+	// message
+	PullRequest string
+	CoAuthors   []*CoAuthor
+
+	// LockToken of the run's project lock, checked before the change is
+	// pushed. Empty if the run isn't locked.
+	LockToken string
+}
+
+// CoAuthor of a change, credited in its commit message
+type CoAuthor struct {
+	Login string
+
+	// Email is the github noreply address of the user
+	Email string
 }
 
 // ServiceDeployParams are the required params to deploy a service
@@ -36,11 +59,34 @@ type DeployResp struct {
 	Hash string
 }
 
+// DeployPlan is a deploy checked against the destination without being made
+type DeployPlan struct {
+	// Destination the deploy was planned against
+	Destination *Destination
+
+	// Policy rules the deploy breaks
+	Policy []*policy.Result
+
+	// Config values the deploy changes
+	Config []*ConfigChange
+}
+
 // ReleaseParams ...
 type ReleaseParams struct {
 	Project string
 	Service string
 	Version string
+
+	// FreezeOverride is the reason given for releasing during a freeze
+	FreezeOverride string
+
+	// Owners of the service, and who released it outside of the owners
+	Owners     []string
+	BreakGlass string
+
+	// LockToken of the run's project lock, checked before the change is
+	// pushed. Empty if the run isn't locked.
+	LockToken string
 }
 
 // ReleaseResp ...
@@ -53,6 +99,14 @@ type RemoveParams struct {
 	Project string
 	Service string
 	Version string
+
+	// Owners of the service, and who removed it outside of the owners
+	Owners     []string
+	BreakGlass string
+
+	// LockToken of the run's project lock, checked before the change is
+	// pushed. Empty if the run isn't locked.
+	LockToken string
 }
 
 // RemoveResp ...
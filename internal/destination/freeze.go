@@ -0,0 +1,15 @@
+package destination
+
+import "time"
+
+// FreezeDir is where ad-hoc freezes are kept in the destination
+const FreezeDir = ".shipper/freezes"
+
+// Freeze is an ad-hoc freeze on a service, which refuses deploys and releases
+// until it is lifted
+type Freeze struct {
+	Service string    `yaml:"service"`
+	Reason  string    `yaml:"reason"`
+	By      string    `yaml:"by"`
+	At      time.Time `yaml:"at"`
+}
@@ -0,0 +1,201 @@
+package destination
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateFuncs are the helpers available in deploy and release templates.
+// They follow the names and argument order of sprig, but are limited to
+// deterministic functions so rendering the same inputs always produces the
+// same manifests. include executes a named template from the set t.
+func templateFuncs(t *template.Template) template.FuncMap {
+	return template.FuncMap{
+		"include": func(name string, data any) (string, error) {
+			buf := &bytes.Buffer{}
+
+			if err := t.ExecuteTemplate(buf, name, data); err != nil {
+				return "", err
+			}
+
+			return buf.String(), nil
+		},
+		"toYaml":   toYaml,
+		"toJson":   toJSON,
+		"indent":   indent,
+		"nindent":  func(n int, s string) string { return "\n" + indent(n, s) },
+		"quote":    func(v any) string { return fmt.Sprintf("%q", toString(v)) },
+		"squote":   func(v any) string { return "'" + strings.ReplaceAll(toString(v), "'", "''") + "'" },
+		"default":  defaultValue,
+		"required": required,
+		"empty":    empty,
+		"coalesce": coalesce,
+		"ternary": func(a, b any, cond bool) any {
+			if cond {
+				return a
+			}
+
+			return b
+		},
+		"toString":   toString,
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"join":       join,
+		"splitList":  func(sep, s string) []string { return strings.Split(s, sep) },
+		"list":       func(v ...any) []any { return v },
+		"dict":       dict,
+		"b64enc":     func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+		"b64dec":     b64dec,
+		"sha256sum": func(s string) string {
+			sum := sha256.Sum256([]byte(s))
+
+			return hex.EncodeToString(sum[:])
+		},
+	}
+}
+
+func toYaml(v any) (string, error) {
+	buf := &bytes.Buffer{}
+
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func toJSON(v any) (string, error) {
+	bts, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(bts), nil
+}
+
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// defaultValue returns the value, or def if the value is empty
+func defaultValue(def any, v ...any) any {
+	if len(v) == 0 || empty(v[0]) {
+		return def
+	}
+
+	return v[0]
+}
+
+func required(msg string, v any) (any, error) {
+	if empty(v) {
+		return nil, errors.New(msg)
+	}
+
+	return v, nil
+}
+
+func coalesce(v ...any) any {
+	for _, val := range v {
+		if !empty(val) {
+			return val
+		}
+	}
+
+	return nil
+}
+
+// empty returns true for nil and zero values, and empty collections
+func empty(v any) bool {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return true
+	}
+
+	switch rv.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rv.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return rv.IsZero()
+	}
+}
+
+func join(sep string, v any) string {
+	rv := reflect.ValueOf(v)
+
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return toString(v)
+	}
+
+	parts := make([]string, rv.Len())
+	for i := range parts {
+		parts[i] = toString(rv.Index(i).Interface())
+	}
+
+	return strings.Join(parts, sep)
+}
+
+func dict(v ...any) (map[string]any, error) {
+	if len(v)%2 != 0 {
+		return nil, fmt.Errorf("dict needs key value pairs")
+	}
+
+	out := map[string]any{}
+
+	for i := 0; i < len(v); i += 2 {
+		out[toString(v[i])] = v[i+1]
+	}
+
+	return out, nil
+}
+
+func b64dec(s string) (string, error) {
+	bts, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+
+	return string(bts), nil
+}
@@ -0,0 +1,86 @@
+package destination
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	data := map[string]any{
+		"name":   "api",
+		"empty":  "",
+		"zero":   0,
+		"list":   []any{"a", "b"},
+		"labels": map[string]any{"app": "api", "team": "core"},
+	}
+
+	tests := []struct {
+		name    string
+		tmpl    string
+		want    string
+		wantErr string
+	}{
+		{name: "include", tmpl: `{{ define "n" }}<{{ . }}>{{ end }}{{ include "n" .name | upper }}`, want: "<API>"},
+		{name: "toYaml", tmpl: `{{ toYaml .labels }}`, want: "app: api\nteam: core"},
+		{name: "toYaml list", tmpl: `{{ toYaml .list }}`, want: "- a\n- b"},
+		{name: "toJson", tmpl: `{{ toJson .labels }}`, want: `{"app":"api","team":"core"}`},
+		{name: "indent", tmpl: `{{ indent 2 "a\nb" }}`, want: "  a\n  b"},
+		{name: "nindent", tmpl: `x:{{ toYaml .labels | nindent 2 }}`, want: "x:\n  app: api\n  team: core"},
+		{name: "quote", tmpl: `{{ quote .zero }} {{ quote "a\"b" }}`, want: `"0" "a\"b"`},
+		{name: "quote nil", tmpl: `{{ quote .missing }}`, want: `""`},
+		{name: "squote", tmpl: `{{ squote "it's" }}`, want: `'it''s'`},
+		{name: "default given", tmpl: `{{ .name | default "web" }}`, want: "api"},
+		{name: "default empty", tmpl: `{{ .empty | default "web" }}`, want: "web"},
+		{name: "default zero", tmpl: `{{ .zero | default 3 }}`, want: "3"},
+		{name: "default missing", tmpl: `{{ .missing | default "web" }}`, want: "web"},
+		{name: "required", tmpl: `{{ required "name is required" .name }}`, want: "api"},
+		{name: "required empty", tmpl: `{{ required "name is required" .empty }}`, wantErr: "name is required"},
+		{name: "empty", tmpl: `{{ empty .empty }} {{ empty .list }} {{ empty .zero }} {{ empty .missing }}`, want: "true false true true"},
+		{name: "coalesce", tmpl: `{{ coalesce .missing .empty .zero .name }}`, want: "api"},
+		{name: "coalesce none", tmpl: `{{ coalesce .missing .empty }}`, want: "<no value>"},
+		{name: "ternary", tmpl: `{{ ternary "yes" "no" true }} {{ ternary "yes" "no" false }}`, want: "yes no"},
+		{name: "toString", tmpl: `{{ toString 12 }}`, want: "12"},
+		{name: "case", tmpl: `{{ upper "api" }} {{ lower "API" }} {{ trim "  api " }}`, want: "API api api"},
+		{name: "trimPrefix and suffix", tmpl: `{{ trimPrefix "v" "v1.2" }} {{ trimSuffix ".yaml" "a.yaml" }}`, want: "1.2 a"},
+		{name: "replace", tmpl: `{{ "a.b.c" | replace "." "-" }}`, want: "a-b-c"},
+		{name: "contains", tmpl: `{{ contains "pi" "api" }} {{ hasPrefix "ap" "api" }} {{ hasSuffix "x" "api" }}`, want: "true true false"},
+		{name: "join", tmpl: `{{ join "," .list }} {{ join "," "a" }}`, want: "a,b a"},
+		{name: "splitList", tmpl: `{{ splitList "," "a,b" | join "+" }}`, want: "a+b"},
+		{name: "list", tmpl: `{{ list 1 "a" | toJson }}`, want: `[1,"a"]`},
+		{name: "dict", tmpl: `{{ dict "a" 1 "b" "x" | toJson }}`, want: `{"a":1,"b":"x"}`},
+		{name: "dict odd args", tmpl: `{{ dict "a" }}`, wantErr: "dict needs key value pairs"},
+		{name: "b64", tmpl: `{{ b64enc "api" }} {{ b64enc "api" | b64dec }}`, want: "YXBp api"},
+		{name: "b64dec invalid", tmpl: `{{ b64dec "!" }}`, wantErr: "illegal base64"},
+		{name: "sha256sum", tmpl: `{{ sha256sum "api" }}`, want: "14c2529eb4498c5d1ffd6915d05bf58a91bdda796af59f41d480d11c099d0479"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.New("test")
+
+			if _, err := tmpl.Funcs(templateFuncs(tmpl)).Parse(tt.tmpl); err != nil {
+				t.Fatalf("failed to parse template: %v", err)
+			}
+
+			out := &strings.Builder{}
+
+			err := tmpl.Execute(out, data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %s", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to execute template: %v", err)
+			}
+
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,441 @@
+package github
+
+import (
+	"context"
+	"crypto/sha1" //nolint:gosec
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cygnetdigital/shipper/internal/destination/gitops"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/go-github/v45/github"
+)
+
+// API is a repository read and written through the github git data API
+// instead of being cloned. Only the files under the checked out paths are
+// fetched, and commits fast forward the branch so concurrent pushes are
+// rejected.
+type API struct {
+	client *github.Client
+	owner  string
+	repo   string
+	branch string
+}
+
+// NewAPI sets up an API repository
+func NewAPI(client *github.Client, owner, repo, branch string) *API {
+	return &API{
+		client: client,
+		owner:  owner,
+		repo:   repo,
+		branch: branch,
+	}
+}
+
+// fetched blobs are kept in memory in parallel, so keep this modest
+var fetchConcurrency = 8
+
+// Checkout downloads the files under the paths into a temporary directory
+func (a *API) Checkout(ctx context.Context, paths ...string) (gitops.Worktree, error) {
+	branch := a.branch
+
+	if branch == "" {
+		repo, _, err := a.client.Repositories.Get(ctx, a.owner, a.repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get repo: %w", err)
+		}
+
+		branch = repo.GetDefaultBranch()
+	}
+
+	ref, _, err := a.client.Git.GetRef(ctx, a.owner, a.repo, "heads/"+branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch %s: %w", branch, err)
+	}
+
+	parent := ref.GetObject().GetSHA()
+
+	commit, _, err := a.client.Git.GetCommit(ctx, a.owner, a.repo, parent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", parent, err)
+	}
+
+	temp, err := os.MkdirTemp("", "dir")
+	if err != nil {
+		return nil, err
+	}
+
+	wt := &apiWorktree{
+		api:      a,
+		root:     temp,
+		branch:   branch,
+		parent:   parent,
+		baseTree: commit.GetTree().GetSHA(),
+		paths:    paths,
+		files:    map[string]*github.TreeEntry{},
+	}
+
+	for _, p := range paths {
+		if err := wt.fetch(ctx, p); err != nil {
+			//nolint:errcheck
+			wt.Close()
+
+			return nil, err
+		}
+	}
+
+	return wt, nil
+}
+
+type apiWorktree struct {
+	api      *API
+	root     string
+	branch   string
+	parent   string
+	baseTree string
+	paths    []string
+
+	// blobs that were checked out, keyed by path
+	files map[string]*github.TreeEntry
+}
+
+func (w *apiWorktree) Root() string {
+	return w.root
+}
+
+func (w *apiWorktree) Base() string {
+	return w.parent
+}
+
+func (w *apiWorktree) Close() error {
+	return os.RemoveAll(w.root)
+}
+
+// fetch all the blobs under dir into the worktree
+func (w *apiWorktree) fetch(ctx context.Context, dir string) error {
+	a := w.api
+
+	treeSHA, err := w.subtree(ctx, dir)
+	if err != nil {
+		return err
+	}
+
+	// the directory doesn't exist yet, or is a single file
+	if treeSHA == "" {
+		return w.fetchFile(ctx, dir)
+	}
+
+	tree, _, err := a.client.Git.GetTree(ctx, a.owner, a.repo, treeSHA, true)
+	if err != nil {
+		return fmt.Errorf("failed to get tree for %s: %w", dir, err)
+	}
+
+	if tree.GetTruncated() {
+		return fmt.Errorf("tree for %s is too large for the github API", dir)
+	}
+
+	blobs := []*github.TreeEntry{}
+
+	for _, e := range tree.Entries {
+		if e.GetType() != "blob" {
+			continue
+		}
+
+		e.Path = github.String(path.Join(dir, e.GetPath()))
+		w.files[e.GetPath()] = e
+		blobs = append(blobs, e)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	sem := make(chan struct{}, fetchConcurrency)
+
+	for _, e := range blobs {
+		wg.Add(1)
+
+		go func(e *github.TreeEntry) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if err := w.writeBlob(ctx, e); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(e)
+	}
+
+	wg.Wait()
+
+	return firstErr
+}
+
+// fetchFile writes the blob at p into the worktree, if there is one. It
+// isn't tracked, as single files are only checked out to be read.
+func (w *apiWorktree) fetchFile(ctx context.Context, p string) error {
+	a := w.api
+	p = path.Clean(p)
+
+	treeSHA, err := w.subtree(ctx, path.Dir(p))
+	if err != nil || treeSHA == "" {
+		return err
+	}
+
+	tree, _, err := a.client.Git.GetTree(ctx, a.owner, a.repo, treeSHA, false)
+	if err != nil {
+		return fmt.Errorf("failed to get tree for %s: %w", p, err)
+	}
+
+	for _, e := range tree.Entries {
+		if e.GetPath() == path.Base(p) && e.GetType() == "blob" {
+			e.Path = github.String(p)
+
+			return w.writeBlob(ctx, e)
+		}
+	}
+
+	return nil
+}
+
+func (w *apiWorktree) writeBlob(ctx context.Context, e *github.TreeEntry) error {
+	a := w.api
+
+	bts, _, err := a.client.Git.GetBlobRaw(ctx, a.owner, a.repo, e.GetSHA())
+	if err != nil {
+		return fmt.Errorf("failed to get blob %s: %w", e.GetPath(), err)
+	}
+
+	fp := filepath.Join(w.root, filepath.FromSlash(e.GetPath()))
+
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(fp, bts, 0644)
+}
+
+// subtree finds the sha of the tree at dir, or an empty string if there is
+// no such tree
+func (w *apiWorktree) subtree(ctx context.Context, dir string) (string, error) {
+	a := w.api
+	sha := w.baseTree
+
+	for _, name := range strings.Split(path.Clean(dir), "/") {
+		if name == "." || name == "" {
+			continue
+		}
+
+		tree, _, err := a.client.Git.GetTree(ctx, a.owner, a.repo, sha, false)
+		if err != nil {
+			return "", fmt.Errorf("failed to get tree for %s: %w", dir, err)
+		}
+
+		sha = ""
+
+		for _, e := range tree.Entries {
+			if e.GetPath() == name && e.GetType() == "tree" {
+				sha = e.GetSHA()
+
+				break
+			}
+		}
+
+		if sha == "" {
+			return "", nil
+		}
+	}
+
+	return sha, nil
+}
+
+// Commit uploads changed files as blobs, then creates a tree and commit on
+// top of the checked out commit. The branch is only fast forwarded, so the
+// commit fails if anything else was pushed since the checkout.
+func (w *apiWorktree) Commit(ctx context.Context, c *gitops.Commit) (string, error) {
+	a := w.api
+
+	entries, err := w.changes(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no changes to commit")
+	}
+
+	tree, _, err := a.client.Git.CreateTree(ctx, a.owner, a.repo, w.baseTree, entries)
+	if err != nil {
+		return "", fmt.Errorf("failed to create tree: %w", err)
+	}
+
+	req := &github.Commit{
+		Message: github.String(c.Message),
+		Tree:    &github.Tree{SHA: tree.SHA},
+		Parents: []*github.Commit{{SHA: github.String(w.parent)}},
+	}
+
+	if c.Author != nil {
+		// github stores dates in UTC to the second, which the signature must
+		// match
+		when := c.Author.When.UTC().Truncate(time.Second)
+
+		req.Author = &github.CommitAuthor{
+			Name:  github.String(c.Author.Name),
+			Email: github.String(c.Author.Email),
+			Date:  &when,
+		}
+		req.Committer = req.Author
+
+		if c.Signer != nil {
+			sig := &object.Signature{Name: c.Author.Name, Email: c.Author.Email, When: when}
+
+			obj := &object.Commit{
+				Author:       *sig,
+				Committer:    *sig,
+				Message:      c.Message,
+				TreeHash:     plumbing.NewHash(tree.GetSHA()),
+				ParentHashes: []plumbing.Hash{plumbing.NewHash(w.parent)},
+			}
+
+			if err := gitops.SignCommit(c.Signer, obj); err != nil {
+				return "", err
+			}
+
+			req.Verification = &github.SignatureVerification{Signature: github.String(obj.PGPSignature)}
+		}
+	} else if c.Signer != nil {
+		return "", fmt.Errorf("signed commits need an author")
+	}
+
+	commit, _, err := a.client.Git.CreateCommit(ctx, a.owner, a.repo, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit: %w", err)
+	}
+
+	_, resp, err := a.client.Git.UpdateRef(ctx, a.owner, a.repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + w.branch),
+		Object: &github.GitObject{SHA: commit.SHA},
+	}, false)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnprocessableEntity {
+			return "", fmt.Errorf("%s was updated by someone else, try again: %w", w.branch, err)
+		}
+
+		return "", fmt.Errorf("failed to update %s: %w", w.branch, err)
+	}
+
+	return commit.GetSHA(), nil
+}
+
+// changes compares the worktree with the checked out blobs, uploading any
+// new or modified files
+func (w *apiWorktree) changes(ctx context.Context) ([]*github.TreeEntry, error) {
+	a := w.api
+	entries := []*github.TreeEntry{}
+	seen := map[string]bool{}
+
+	for _, dir := range w.paths {
+		root := filepath.Join(w.root, filepath.FromSlash(dir))
+
+		if info, err := os.Stat(root); err == nil && !info.IsDir() {
+			continue
+		}
+
+		err := filepath.WalkDir(root, func(fp string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			rel, err := filepath.Rel(w.root, fp)
+			if err != nil {
+				return err
+			}
+
+			p := filepath.ToSlash(rel)
+			seen[p] = true
+
+			bts, err := os.ReadFile(fp)
+			if err != nil {
+				return err
+			}
+
+			mode := "100644"
+
+			if orig, ok := w.files[p]; ok {
+				if orig.GetSHA() == blobHash(bts) {
+					return nil
+				}
+
+				mode = orig.GetMode()
+			}
+
+			blob, _, err := a.client.Git.CreateBlob(ctx, a.owner, a.repo, &github.Blob{
+				Content:  github.String(base64.StdEncoding.EncodeToString(bts)),
+				Encoding: github.String("base64"),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create blob for %s: %w", p, err)
+			}
+
+			entries = append(entries, &github.TreeEntry{
+				Path: github.String(p),
+				Mode: github.String(mode),
+				Type: github.String("blob"),
+				SHA:  blob.SHA,
+			})
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// a nil sha removes the file from the tree
+	for p, orig := range w.files {
+		if !seen[p] {
+			entries = append(entries, &github.TreeEntry{
+				Path: github.String(p),
+				Mode: orig.Mode,
+				Type: github.String("blob"),
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+// blobHash is the git object hash of a blob with the given content
+func blobHash(content []byte) string {
+	//nolint:gosec
+	h := sha1.New()
+	fmt.Fprintf(h, "blob %d\x00", len(content))
+	h.Write(content)
+
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
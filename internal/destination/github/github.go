@@ -2,84 +2,41 @@ package github
 
 import (
 	"fmt"
-	"net/url"
-	"os"
 
 	"github.com/cygnetdigital/shipper"
-	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/cygnetdigital/shipper/internal/auth"
+	"github.com/cygnetdigital/shipper/internal/destination/gitops"
+	"github.com/cygnetdigital/shipper/internal/gitcache"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 )
 
-// Github destination is capable of deploying manifests to github
-type Github struct {
-	projName     string
-	repo         string
-	templatePath string
-	bundlePath   string
-	registry     string
-	namespace    string
-	auth         *http.BasicAuth
-}
-
-// NewGithub sets up a git destination
-func NewGithub(proj *shipper.Project, ghToken string) *Github {
-	return &Github{
-		projName:     proj.Name,
-		repo:         proj.Gitops.Repo,
-		templatePath: proj.Gitops.TemplatePath,
-		bundlePath:   proj.Gitops.ManifestPath,
-		registry:     proj.RegistryPrefix,
-		namespace:    proj.Gitops.Namespace,
-		auth:         &http.BasicAuth{Username: "username", Password: ghToken},
-	}
-}
-
-// clone the repo
-func (s *Github) clone() (*git.Repository, string, error) {
-	temp, err := os.MkdirTemp("", "dir")
+// NewGithub sets up a gitops destination for a repo hosted on github, using
+// the github credentials for access. The repo is cloned, using the cache if
+// given, unless the gitops mode is "api", in which case it is read and written
+// through the github API.
+func NewGithub(proj *shipper.Project, creds auth.Credentials, cache *gitcache.Cache) *gitops.Gitops {
+	owner, repo, err := auth.SplitRepo(proj.Gitops.Repo)
 	if err != nil {
-		return nil, "", err
-	}
-
-	uri, err := url.Parse(s.repo)
-	if err != nil {
-		return nil, "", err
-	}
-
-	if uri.Scheme == "" {
-		uri.Scheme = "https"
+		panic(err.Error())
 	}
 
-	repo, err := git.PlainClone(temp, false, &git.CloneOptions{
-		URL:   uri.String(),
-		Depth: 1,
-		Auth:  s.auth,
-	})
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to clone repo: %w", err)
-	}
+	tokens := creds.TokenSource(owner, repo)
 
-	return repo, temp, nil
-}
+	var dest *gitops.Gitops
 
-func (s *Github) commit(msg string, repo *git.Repository) (string, error) {
-	wt, err := repo.Worktree()
-	if err != nil {
-		return "", fmt.Errorf("failed to get worktree: %w", err)
-	}
+	if proj.Gitops.Mode == "api" {
+		client := auth.NewGithubClient(tokens)
 
-	if err := wt.AddGlob(s.bundlePath + "/*"); err != nil {
-		return "", fmt.Errorf("failed to add files to worktree: %w", err)
-	}
+		dest = gitops.New(proj, NewAPI(client, owner, repo, proj.Gitops.Branch))
+	} else {
+		remote := gitops.NewRemote(proj.Gitops.Repo, proj.Gitops.Branch, func() (transport.AuthMethod, error) {
+			return auth.GitAuth(tokens)
+		}, cache)
 
-	hash, err := wt.Commit(msg, &git.CommitOptions{All: true})
-	if err != nil {
-		return "", fmt.Errorf("failed to commit: %w", err)
+		dest = gitops.New(proj, remote)
 	}
 
-	if err := repo.Push(&git.PushOptions{RemoteName: "origin", Auth: s.auth}); err != nil {
-		return "", fmt.Errorf("failed to push: %w", err)
-	}
+	dest.SetRepoURL(fmt.Sprintf("https://github.com/%s/%s.git", owner, repo))
 
-	return hash.String(), nil
+	return dest
 }
@@ -0,0 +1,123 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"gopkg.in/yaml.v3"
+)
+
+// Approve adds a signoff to the approval record for the plan, recording the
+// plan if it hasn't been approved before
+func (s *Gitops) Approve(ctx context.Context, project string, plan *destination.Approval, signoff *destination.ApprovalSignoff) error {
+	if project != s.projName {
+		return fmt.Errorf("project %s not supported", project)
+	}
+
+	wt, err := s.repo.Checkout(ctx, s.approvalDir())
+	if err != nil {
+		return fmt.Errorf("failed to checkout gitops repo: %w", err)
+	}
+
+	//nolint:errcheck
+	defer wt.Close()
+
+	fp := s.approvalFile(wt, plan.ID)
+
+	a, err := readApproval(fp)
+	if err != nil {
+		return err
+	}
+
+	if a == nil {
+		record := *plan
+		record.Approvals = nil
+		a = &record
+	}
+
+	for _, existing := range a.Approvals {
+		if existing.By == signoff.By {
+			return fmt.Errorf("plan %s is already approved by %s", a.ID, signoff.By)
+		}
+	}
+
+	a.Approvals = append(a.Approvals, signoff)
+
+	if err := writeApproval(fp, a); err != nil {
+		return err
+	}
+
+	msg := &commitMessage{
+		Summary:   fmt.Sprintf("Approving %s %s/%s/%s by %s", a.Operation, a.Project, a.Service, a.Version, signoff.By),
+		Operation: "approve",
+		Services:  []string{a.Service},
+	}
+
+	if _, err := s.commit(ctx, wt, msg); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return nil
+}
+
+// GetApproval returns the approval record for the plan, or nil if approval
+// hasn't been requested
+func (s *Gitops) GetApproval(ctx context.Context, project, id string) (*destination.Approval, error) {
+	if project != s.projName {
+		return nil, fmt.Errorf("project %s not supported", project)
+	}
+
+	wt, err := s.repo.Checkout(ctx, s.approvalDir())
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkout gitops repo: %w", err)
+	}
+
+	//nolint:errcheck
+	defer wt.Close()
+
+	return readApproval(s.approvalFile(wt, id))
+}
+
+func (s *Gitops) approvalDir() string {
+	return destination.ApprovalDir + "/" + s.projName
+}
+
+func (s *Gitops) approvalFile(wt Worktree, id string) string {
+	return filepath.Join(wt.Root(), filepath.FromSlash(s.approvalDir()), filepath.Base(id)+".yaml")
+}
+
+func readApproval(fp string) (*destination.Approval, error) {
+	bts, err := os.ReadFile(fp)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read approval: %w", err)
+	}
+
+	a := &destination.Approval{}
+
+	if err := yaml.Unmarshal(bts, a); err != nil {
+		return nil, fmt.Errorf("failed to parse approval: %w", err)
+	}
+
+	return a, nil
+}
+
+func writeApproval(fp string, a *destination.Approval) error {
+	bts, err := yaml.Marshal(a)
+	if err != nil {
+		return fmt.Errorf("failed to encode approval: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(fp, bts, 0644)
+}
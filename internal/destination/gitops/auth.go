@@ -0,0 +1,44 @@
+package gitops
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// SSHAuth authenticates with the key file if one is given, otherwise with the
+// running ssh agent.
+func SSHAuth(repoURL, keyFile, passphrase string) AuthFunc {
+	return func() (transport.AuthMethod, error) {
+		user := "git"
+
+		if ep, err := transport.NewEndpoint(repoURL); err == nil && ep.User != "" {
+			user = ep.User
+		}
+
+		if keyFile != "" {
+			auth, err := ssh.NewPublicKeysFromFile(user, keyFile, passphrase)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load ssh key %s: %w", keyFile, err)
+			}
+
+			return auth, nil
+		}
+
+		auth, err := ssh.NewSSHAgentAuth(user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to use ssh agent: %w", err)
+		}
+
+		return auth, nil
+	}
+}
+
+// BasicAuth authenticates over https with a username and password
+func BasicAuth(username, password string) AuthFunc {
+	return func() (transport.AuthMethod, error) {
+		return &http.BasicAuth{Username: username, Password: password}, nil
+	}
+}
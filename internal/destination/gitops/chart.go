@@ -0,0 +1,125 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/cygnetdigital/shipper/internal/conf"
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"github.com/cygnetdigital/shipper/internal/helm"
+)
+
+// argoNamespace is where Argo CD Applications go by default
+const argoNamespace = "argocd"
+
+// isChart returns true if the template is a helm chart, either an oci chart
+// or vendored in the templates dir
+func isChart(templatesDir, template string) bool {
+	return helm.IsOCI(template) || destination.IsChart(path.Join(templatesDir, "deploy", template))
+}
+
+// renderBundle renders the service's deploy bundle from its template or
+// chart, along with its config map and secrets
+func (s *Gitops) renderBundle(ctx context.Context, dirs *LocalDirs, sp *destination.ServiceDeployParams, args *destination.DeployContext) ([]*destination.RenderedFile, error) {
+	deploy := &sp.Config.Deploy
+	templatesDir := dirs.Templates
+
+	var (
+		files []*destination.RenderedFile
+		err   error
+	)
+
+	if isChart(templatesDir, deploy.Template) {
+		files, err = s.renderChart(ctx, dirs, deploy, args)
+		if err == nil {
+			err = destination.AttributeDeployBundle(args, files)
+		}
+	} else {
+		files, err = destination.RenderDeployBundle(templatesDir, deploy.Template, args)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	files, err = destination.AddConfigMap(args, deploy.Config, deploy.ConfigMode, files)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.addSecrets(dirs, sp.Config, args, files)
+}
+
+// renderChart renders the chart as manifests, or as an Application or
+// HelmRelease for a gitops controller to install
+func (s *Gitops) renderChart(ctx context.Context, dirs *LocalDirs, deploy *conf.ServiceDeploy, args *destination.DeployContext) ([]*destination.RenderedFile, error) {
+	ref, opts := deploy.Template, deploy.Chart
+
+	if _, ok := args.Params["shipper"]; ok {
+		return nil, fmt.Errorf("the shipper value is reserved for the deploy context")
+	}
+
+	if helm.IsOCI(ref) && opts.Version == "" {
+		return nil, fmt.Errorf("a chart version is required for %s", ref)
+	}
+
+	switch opts.Output {
+	case "", destination.ChartManifests:
+		chart := ref
+		if !helm.IsOCI(ref) {
+			chart = path.Join(dirs.Templates, "deploy", ref)
+		}
+
+		data, err := s.charts.Template(ctx, args.SlugNameVersion, chart, opts.Version, args.Namespace, destination.ChartValues(args))
+		if err != nil {
+			return nil, err
+		}
+
+		return []*destination.RenderedFile{{Name: path.Base(ref) + ".yaml", Data: data}}, nil
+	case destination.ChartApplication:
+		ns := opts.Namespace
+		if ns == "" {
+			ns = argoNamespace
+		}
+
+		return destination.RenderChartApplication(args, s.chartSource(ref, opts.Version, dirs.Revision), ns)
+	case destination.ChartHelmRelease:
+		ns := opts.Namespace
+		if ns == "" {
+			ns = s.namespace
+		}
+
+		return destination.RenderChartHelmRelease(args, s.chartSource(ref, opts.Version, dirs.Revision), ns)
+	default:
+		return nil, fmt.Errorf("unknown chart output '%s'", opts.Output)
+	}
+}
+
+// chartSource is where a gitops controller gets the chart from: the oci
+// registry, or the templates in the gitops repo. Vendored charts are pinned
+// to the revision the deploy was rendered from; the commit being written
+// can't name itself, but it has the same templates as its parent.
+func (s *Gitops) chartSource(ref, version, revision string) *destination.ChartSource {
+	if helm.IsOCI(ref) {
+		i := strings.LastIndex(ref, "/")
+
+		return &destination.ChartSource{RepoURL: ref[:i], Chart: ref[i+1:], Version: version}
+	}
+
+	// local renders follow the branch, as they're never deployed
+	if revision == "" {
+		revision = s.branch
+	}
+
+	if revision == "" {
+		revision = "HEAD"
+	}
+
+	return &destination.ChartSource{
+		RepoURL: s.repoURL,
+		Path:    path.Join(s.templatePath, "deploy", ref),
+		Version: revision,
+	}
+}
@@ -0,0 +1,52 @@
+package gitops
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+)
+
+func TestChartSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		branch   string
+		ref      string
+		revision string
+		want     *destination.ChartSource
+	}{
+		{
+			name: "oci",
+			ref:  "oci://reg.example.com/charts/api",
+			want: &destination.ChartSource{RepoURL: "oci://reg.example.com/charts", Chart: "api", Version: "1.2.3"},
+		},
+		{
+			name:     "vendored, pinned to the checkout",
+			branch:   "main",
+			ref:      "api",
+			revision: "0123456789abcdef0123456789abcdef01234567",
+			want:     &destination.ChartSource{RepoURL: "https://github.com/org/gitops", Path: "templates/deploy/api", Version: "0123456789abcdef0123456789abcdef01234567"},
+		},
+		{
+			name:   "vendored, rendered locally",
+			branch: "main",
+			ref:    "api",
+			want:   &destination.ChartSource{RepoURL: "https://github.com/org/gitops", Path: "templates/deploy/api", Version: "main"},
+		},
+		{
+			name: "vendored, rendered locally without a branch",
+			ref:  "api",
+			want: &destination.ChartSource{RepoURL: "https://github.com/org/gitops", Path: "templates/deploy/api", Version: "HEAD"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Gitops{repoURL: "https://github.com/org/gitops", templatePath: "templates", branch: tt.branch}
+
+			if got := s.chartSource(tt.ref, "1.2.3", tt.revision); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
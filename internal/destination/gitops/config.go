@@ -0,0 +1,37 @@
+package gitops
+
+import (
+	"fmt"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+)
+
+// diffConfig returns how the config of the services changes from their last
+// deploy in the destination, for those with config in a config map
+func diffConfig(dest *destination.Destination, p *destination.DeployParams) ([]*destination.ConfigChange, error) {
+	changes := []*destination.ConfigChange{}
+
+	for _, sp := range p.Services {
+		mode := sp.Config.Deploy.ConfigMode
+		if mode == "" || mode == destination.ConfigEnv {
+			continue
+		}
+
+		next, err := destination.ConfigData(sp.Config.Deploy.Config, mode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid config for %s: %w", sp.Config.Name, err)
+		}
+
+		var last map[string]string
+
+		if svc := dest.Services.LookupByProjectAndName(p.ProjectName, sp.Config.Name); svc != nil && len(svc.Deploys) > 0 {
+			if last, err = svc.Deploys[len(svc.Deploys)-1].ConfigData(); err != nil {
+				return nil, err
+			}
+		}
+
+		changes = append(changes, destination.DiffConfig(sp.Config.Name, last, next)...)
+	}
+
+	return changes, nil
+}
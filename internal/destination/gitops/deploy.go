@@ -0,0 +1,228 @@
+package gitops
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/cygnetdigital/shipper/internal/conf"
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"github.com/cygnetdigital/shipper/internal/policy"
+)
+
+// Deploy to the destination
+func (s *Gitops) Deploy(ctx context.Context, p *destination.DeployParams) (*destination.DeployResp, error) {
+	if p.ProjectName != s.projName {
+		return nil, fmt.Errorf("project %s not supported", p.ProjectName)
+	}
+
+	wt, err := s.repo.Checkout(ctx, lockPaths(p.LockToken, s.checkoutPaths(s.bundlePath, s.templatePath))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkout gitops repo: %w", err)
+	}
+
+	//nolint:errcheck
+	defer wt.Close()
+
+	rootPath := wt.Root()
+
+	svcs, err := destination.LoadProjectServices(path.Join(rootPath, s.bundlePath), s.projName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load k8s manifests: %w", err)
+	}
+
+	for _, sp := range p.Services {
+		svc := svcs.LookupByProjectAndName(p.ProjectName, sp.Config.Name)
+
+		if svc != nil && svc.HasVersion(sp.Version) {
+			return nil, fmt.Errorf("version already exists")
+		}
+	}
+
+	bundles, results, err := s.renderServices(ctx, s.repoDirs(wt), p.Services)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.Denied(results) {
+		return nil, &policy.DeniedError{Results: results}
+	}
+
+	manifestRoot := path.Join(rootPath, s.bundlePath)
+
+	for _, b := range bundles {
+		if err := destination.WriteDeployBundle(manifestRoot, b.args, b.files); err != nil {
+			return nil, fmt.Errorf("failed to write bundle: %w", err)
+		}
+	}
+
+	names := []string{}
+	for _, sp := range p.Services {
+		names = append(names, sp.Config.Name)
+	}
+
+	if err := s.writeOutput(manifestRoot, names...); err != nil {
+		return nil, fmt.Errorf("failed to write %s output: %w", s.output, err)
+	}
+
+	if err := s.checkLock(wt, p.LockToken); err != nil {
+		return nil, err
+	}
+
+	msg := &commitMessage{
+		Summary:     fmt.Sprintf("Deploying %s", p.ProjectName),
+		Operation:   "deploy",
+		PullRequest: p.PullRequest,
+		CoAuthors:   p.CoAuthors,
+	}
+
+	if len(p.Services) == 1 {
+		msg.Summary = fmt.Sprintf("Deploying %s/%s", p.ProjectName, p.Services[0].Config.Name)
+	}
+
+	if p.FreezeOverride != "" {
+		msg.addNote("Freeze overridden: %s", p.FreezeOverride)
+	}
+
+	for _, sp := range p.Services {
+		msg.Services = append(msg.Services, sp.Config.Name)
+		msg.addOwners(sp.Config.Name, sp.Config.Owners, "")
+	}
+
+	hash, err := s.commit(ctx, wt, msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return &destination.DeployResp{Hash: hash}, nil
+}
+
+// NewDeployContext builds the context a service's deploy template is rendered
+// with, validating its params against the template spec in templateRoot
+func (s *Gitops) NewDeployContext(ctx context.Context, templateRoot string, sp *destination.ServiceDeployParams) (*destination.DeployContext, error) {
+	slug, err := destination.SlugifyServiceName(sp.Config.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to slugify service name: %w", err)
+	}
+
+	slugNameVersion := fmt.Sprintf("%s-%s", slug, sp.Version)
+
+	dv := map[string]string{}
+
+	var configMap string
+
+	switch sp.Config.Deploy.ConfigMode {
+	case "", destination.ConfigEnv:
+		if dv, err = setupDeployVariables(sp.Config.Deploy.Config); err != nil {
+			return nil, fmt.Errorf("failed to setup deploy variables: %w", err)
+		}
+	case destination.ConfigConfigMap, destination.ConfigFiles:
+		configMap = destination.ConfigMapName(slugNameVersion)
+	default:
+		return nil, fmt.Errorf("unknown config mode '%s' for %s", sp.Config.Deploy.ConfigMode, sp.Config.Name)
+	}
+
+	params, err := resolveParams(templateRoot, sp)
+	if err != nil {
+		return nil, err
+	}
+
+	secretNames, secretVariables, secretMounts, err := resolveSecrets(slugNameVersion, &sp.Config.Deploy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets for %s: %w", sp.Config.Name, err)
+	}
+
+	image := path.Join(s.registry, sp.Config.ImageName())
+	deployImage := fmt.Sprintf("%s:%s", image, sp.ImageTag)
+
+	var digest string
+
+	if s.pinDigest {
+		digest, err = s.imageDigest(ctx, image, sp.ImageTag)
+		if err != nil {
+			return nil, err
+		}
+
+		deployImage = fmt.Sprintf("%s@%s", image, digest)
+	}
+
+	return &destination.DeployContext{
+		Project:         s.projName,
+		Name:            sp.Config.Name,
+		Version:         sp.Version,
+		SlugName:        slug,
+		SlugNameVersion: slugNameVersion,
+		DeployImage:     deployImage,
+		ImageDigest:     digest,
+		DeployVariables: dv,
+		ConfigMap:       configMap,
+		SecretMounts:    secretMounts,
+		SecretVariables: secretVariables,
+		Secrets:         secretNames,
+		Namespace:       s.namespace,
+		Params:          params,
+	}, nil
+}
+
+// placeholderDigest stands in for image digests when rendering offline
+const placeholderDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+// imageDigest resolves the image tag to a digest in the registry, or the
+// placeholder digest when offline
+func (s *Gitops) imageDigest(ctx context.Context, image, tag string) (string, error) {
+	if s.offline {
+		return placeholderDigest, nil
+	}
+
+	desc, err := s.images.Manifest(ctx, image, tag)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve digest for %s:%s: %w", image, tag, err)
+	}
+
+	if desc.Digest == "" {
+		return "", fmt.Errorf("registry returned no digest for %s:%s", image, tag)
+	}
+
+	return desc.Digest, nil
+}
+
+// resolveParams validates the service's params against the spec of its
+// template in templateRoot, filling in defaults
+func resolveParams(templateRoot string, sp *destination.ServiceDeployParams) (map[string]any, error) {
+	templateDir := path.Join(templateRoot, "deploy", sp.Config.Deploy.Template)
+
+	// charts take any values, unless they declare params like templates
+	if isChart(templateRoot, sp.Config.Deploy.Template) && !destination.HasTemplateSpec(templateDir) {
+		return sp.Config.Deploy.Params, nil
+	}
+
+	spec, err := destination.LoadTemplateSpec(templateDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load template spec: %w", err)
+	}
+
+	params, err := spec.Resolve(sp.Config.Deploy.Params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid params for %s: %w", sp.Config.Name, err)
+	}
+
+	return params, nil
+}
+
+func setupDeployVariables(configs []*conf.ServiceConfigItem) (map[string]string, error) {
+	out := map[string]string{}
+
+	for _, c := range configs {
+		v, err := json.Marshal(c.Values)
+		if err != nil {
+			return nil, err
+		}
+
+		key := fmt.Sprintf("SHIPPER_%s", strings.ToUpper(c.Name))
+		out[key] = string(v)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,168 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"gopkg.in/yaml.v3"
+)
+
+// Freeze a service by committing it to the project freeze file, replacing
+// any existing freeze on the service
+func (s *Gitops) Freeze(ctx context.Context, project string, f *destination.Freeze) error {
+	if project != s.projName {
+		return fmt.Errorf("project %s not supported", project)
+	}
+
+	wt, err := s.repo.Checkout(ctx, destination.FreezeDir)
+	if err != nil {
+		return fmt.Errorf("failed to checkout gitops repo: %w", err)
+	}
+
+	//nolint:errcheck
+	defer wt.Close()
+
+	freezes, err := readFreezes(s.freezeFile(wt))
+	if err != nil {
+		return err
+	}
+
+	out := []*destination.Freeze{f}
+
+	for _, existing := range freezes {
+		if existing.Service != f.Service {
+			out = append(out, existing)
+		}
+	}
+
+	if err := writeFreezes(s.freezeFile(wt), out); err != nil {
+		return err
+	}
+
+	msg := &commitMessage{
+		Summary:   fmt.Sprintf("Freezing %s/%s by %s", project, f.Service, f.By),
+		Body:      f.Reason,
+		Operation: "freeze",
+		Services:  []string{f.Service},
+	}
+
+	if _, err := s.commit(ctx, wt, msg); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return nil
+}
+
+// Unfreeze a service by removing it from the project freeze file
+func (s *Gitops) Unfreeze(ctx context.Context, project, service string) error {
+	if project != s.projName {
+		return fmt.Errorf("project %s not supported", project)
+	}
+
+	wt, err := s.repo.Checkout(ctx, destination.FreezeDir)
+	if err != nil {
+		return fmt.Errorf("failed to checkout gitops repo: %w", err)
+	}
+
+	//nolint:errcheck
+	defer wt.Close()
+
+	freezes, err := readFreezes(s.freezeFile(wt))
+	if err != nil {
+		return err
+	}
+
+	out := []*destination.Freeze{}
+
+	for _, existing := range freezes {
+		if existing.Service != service {
+			out = append(out, existing)
+		}
+	}
+
+	if len(out) == len(freezes) {
+		return fmt.Errorf("%s is not frozen", service)
+	}
+
+	if err := writeFreezes(s.freezeFile(wt), out); err != nil {
+		return err
+	}
+
+	msg := &commitMessage{
+		Summary:   fmt.Sprintf("Unfreezing %s/%s", project, service),
+		Operation: "unfreeze",
+		Services:  []string{service},
+	}
+
+	if _, err := s.commit(ctx, wt, msg); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return nil
+}
+
+// GetFreezes returns the ad-hoc freezes on the project's services
+func (s *Gitops) GetFreezes(ctx context.Context, project string) ([]*destination.Freeze, error) {
+	if project != s.projName {
+		return nil, fmt.Errorf("project %s not supported", project)
+	}
+
+	wt, err := s.repo.Checkout(ctx, destination.FreezeDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkout gitops repo: %w", err)
+	}
+
+	//nolint:errcheck
+	defer wt.Close()
+
+	return readFreezes(s.freezeFile(wt))
+}
+
+func (s *Gitops) freezeFile(wt Worktree) string {
+	return filepath.Join(wt.Root(), filepath.FromSlash(destination.FreezeDir), s.projName+".yaml")
+}
+
+func readFreezes(fp string) ([]*destination.Freeze, error) {
+	bts, err := os.ReadFile(fp)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read freeze file: %w", err)
+	}
+
+	freezes := []*destination.Freeze{}
+
+	if err := yaml.Unmarshal(bts, &freezes); err != nil {
+		return nil, fmt.Errorf("failed to parse freeze file: %w", err)
+	}
+
+	return freezes, nil
+}
+
+// writeFreezes writes the freezes, removing the file once there are none
+func writeFreezes(fp string, freezes []*destination.Freeze) error {
+	if len(freezes) == 0 {
+		if err := os.Remove(fp); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove freeze file: %w", err)
+		}
+
+		return nil
+	}
+
+	bts, err := yaml.Marshal(freezes)
+	if err != nil {
+		return fmt.Errorf("failed to encode freezes: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(fp, bts, 0644)
+}
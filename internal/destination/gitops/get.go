@@ -0,0 +1,39 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+)
+
+// Get the destination state
+func (s *Gitops) Get(ctx context.Context, projectName string) (*destination.Destination, error) {
+	if projectName != s.projName {
+		return nil, fmt.Errorf("project %s not supported", projectName)
+	}
+
+	wt, err := s.repo.Checkout(ctx, s.bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkout gitops repo: %w", err)
+	}
+
+	//nolint:errcheck
+	defer wt.Close()
+
+	return s.load(wt.Root())
+}
+
+// load the destination state from a checkout of the gitops repo at root
+func (s *Gitops) load(root string) (*destination.Destination, error) {
+	services, err := destination.LoadProjectServices(path.Join(root, s.bundlePath), s.projName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load k8s manifests: %w", err)
+	}
+
+	return &destination.Destination{
+		ProjectName: s.projName,
+		Services:    services.FilterByProject(s.projName),
+	}, nil
+}
@@ -0,0 +1,108 @@
+// Package gitops provides a destination which renders manifests into any git
+// repository, leaving it to a gitops controller to apply them.
+package gitops
+
+import (
+	"context"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/conf"
+	"github.com/cygnetdigital/shipper/internal/helm"
+	"github.com/cygnetdigital/shipper/internal/registry"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Gitops destination is capable of deploying manifests to a git repository
+type Gitops struct {
+	projName     string
+	templatePath string
+	bundlePath   string
+	crdPath      string
+	secrets      conf.ProjectSecrets
+	output       string
+	registry     string
+	namespace    string
+	pinDigest    bool
+	images       *registry.Client
+	charts       *helm.Client
+	repo         Repository
+
+	// repoURL and branch gitops controllers pull vendored charts from
+	repoURL string
+	branch  string
+
+	// skipValidation of rendered manifests
+	skipValidation bool
+
+	// skipSecretCheck of the secrets referenced by services
+	skipSecretCheck bool
+
+	// localDirs to render and check deploys with instead of the repo
+	localDirs *LocalDirs
+
+	// offline renders pinned images with a placeholder digest, instead of
+	// resolving their tags in the registry
+	offline bool
+
+	// ageKeyFile to decrypt sealed secret values with
+	ageKeyFile string
+
+	// author and signer of commits, and the template for their messages
+	author          AuthorFunc
+	signer          Signer
+	messageTemplate string
+}
+
+// AuthorFunc returns the author of commits
+type AuthorFunc func(ctx context.Context) (*object.Signature, error)
+
+// New sets up a gitops destination using the repository to read and write
+// manifests
+func New(proj *shipper.Project, repo Repository) *Gitops {
+	return &Gitops{
+		projName:     proj.Name,
+		templatePath: proj.Gitops.TemplatePath,
+		bundlePath:   proj.Gitops.ManifestPath,
+		crdPath:      proj.Gitops.CRDPath,
+		secrets:      proj.Gitops.Secrets,
+		output:       proj.Gitops.Output,
+		registry:     proj.RegistryPrefix,
+		namespace:    proj.Gitops.Namespace,
+		pinDigest:    proj.Image.PinDigest,
+		images:       registry.NewClient(),
+		charts:       helm.New(""),
+		repo:         repo,
+		repoURL:      NormalizeURL(proj.Gitops.Repo),
+		branch:       proj.Gitops.Branch,
+
+		messageTemplate: proj.Gitops.CommitMessage,
+	}
+}
+
+// SetChartCache turns caching of pulled oci charts on or off
+func (s *Gitops) SetChartCache(enabled bool) {
+	if enabled {
+		s.charts = helm.New("")
+	} else {
+		s.charts = helm.NewUncached()
+	}
+}
+
+// SetOffline renders without contacting the registry, for renders which are
+// never deployed
+func (s *Gitops) SetOffline(offline bool) {
+	s.offline = offline
+}
+
+// SetRepoURL sets the url gitops controllers pull the repo from, when it
+// differs from the configured repo
+func (s *Gitops) SetRepoURL(repoURL string) {
+	s.repoURL = repoURL
+}
+
+// SetCommitter sets the author of commits, and the signer to sign them with.
+// Either may be nil to use the git config and leave commits unsigned.
+func (s *Gitops) SetCommitter(author AuthorFunc, signer Signer) {
+	s.author = author
+	s.signer = signer
+}
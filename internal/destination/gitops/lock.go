@@ -0,0 +1,195 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"gopkg.in/yaml.v3"
+)
+
+// Lock the project by committing a lock file to the repo. The push is
+// rejected if someone else committed first, so only one run can win.
+func (s *Gitops) Lock(ctx context.Context, project, holder, token, operation string, ttl time.Duration) (*destination.Lock, error) {
+	if project != s.projName {
+		return nil, fmt.Errorf("project %s not supported", project)
+	}
+
+	wt, err := s.repo.Checkout(ctx, destination.LockDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkout gitops repo: %w", err)
+	}
+
+	//nolint:errcheck
+	defer wt.Close()
+
+	now := time.Now().UTC()
+
+	existing, err := readLock(s.lockFile(wt))
+	if err != nil {
+		return nil, err
+	}
+
+	if token == "" {
+		return nil, fmt.Errorf("a lock token is required")
+	}
+
+	if existing != nil && existing.Token != token && !existing.Expired(now) {
+		return nil, &destination.LockedError{Lock: existing}
+	}
+
+	lock := &destination.Lock{
+		Project:   project,
+		Holder:    holder,
+		Token:     token,
+		Operation: operation,
+		Acquired:  now,
+		Expires:   now.Add(ttl),
+	}
+
+	if err := writeLock(s.lockFile(wt), lock); err != nil {
+		return nil, err
+	}
+
+	msg := &commitMessage{
+		Summary:   fmt.Sprintf("Locking %s for %s by %s", project, operation, holder),
+		Operation: "lock",
+	}
+
+	if _, err := s.commit(ctx, wt, msg); err != nil {
+		return nil, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+
+	return lock, nil
+}
+
+// Unlock the project. Locks taken by another run are only removed once they
+// have expired, or if force is set.
+func (s *Gitops) Unlock(ctx context.Context, project, token string, force bool) error {
+	if project != s.projName {
+		return fmt.Errorf("project %s not supported", project)
+	}
+
+	wt, err := s.repo.Checkout(ctx, destination.LockDir)
+	if err != nil {
+		return fmt.Errorf("failed to checkout gitops repo: %w", err)
+	}
+
+	//nolint:errcheck
+	defer wt.Close()
+
+	existing, err := readLock(s.lockFile(wt))
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		return nil
+	}
+
+	if existing.Token != token && !existing.Expired(time.Now()) && !force {
+		return &destination.LockedError{Lock: existing}
+	}
+
+	if err := os.Remove(s.lockFile(wt)); err != nil {
+		return fmt.Errorf("failed to remove lock file: %w", err)
+	}
+
+	msg := &commitMessage{
+		Summary:   fmt.Sprintf("Unlocking %s held by %s", project, existing.Holder),
+		Operation: "unlock",
+	}
+
+	if _, err := s.commit(ctx, wt, msg); err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+
+	return nil
+}
+
+// GetLock returns the current lock on the project, or nil if it isn't locked
+func (s *Gitops) GetLock(ctx context.Context, project string) (*destination.Lock, error) {
+	if project != s.projName {
+		return nil, fmt.Errorf("project %s not supported", project)
+	}
+
+	wt, err := s.repo.Checkout(ctx, destination.LockDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkout gitops repo: %w", err)
+	}
+
+	//nolint:errcheck
+	defer wt.Close()
+
+	return readLock(s.lockFile(wt))
+}
+
+// checkLock fails if the project lock in the worktree isn't held by the run
+// with the token. Checked in the checkout a change is committed from, the
+// push is rejected if the lock changes before it lands.
+func (s *Gitops) checkLock(wt Worktree, token string) error {
+	if token == "" {
+		return nil
+	}
+
+	lock, err := readLock(s.lockFile(wt))
+	if err != nil {
+		return err
+	}
+
+	if lock == nil || lock.Token != token || lock.Expired(time.Now()) {
+		return &destination.LockLostError{Project: s.projName, Lock: lock}
+	}
+
+	return nil
+}
+
+// lockPaths adds the lock dir to the paths checked out for a change, if the
+// change is made under a lock
+func lockPaths(token string, paths []string) []string {
+	if token == "" {
+		return paths
+	}
+
+	return append(paths, destination.LockDir)
+}
+
+func (s *Gitops) lockFile(wt Worktree) string {
+	return filepath.Join(wt.Root(), filepath.FromSlash(destination.LockDir), s.projName+".yaml")
+}
+
+func readLock(fp string) (*destination.Lock, error) {
+	bts, err := os.ReadFile(fp)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	lock := &destination.Lock{}
+
+	if err := yaml.Unmarshal(bts, lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file: %w", err)
+	}
+
+	return lock, nil
+}
+
+func writeLock(fp string, lock *destination.Lock) error {
+	bts, err := yaml.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("failed to encode lock: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(fp, bts, 0644)
+}
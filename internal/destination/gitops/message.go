@@ -0,0 +1,130 @@
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+)
+
+// defaultCommitMessage is the template used when the project doesn't set one
+const defaultCommitMessage = `{{ .Summary }}
+{{- if .Body }}
+
+{{ .Body }}
+{{- end }}
+{{- if .PullRequest }}
+
+Pull request: {{ .PullRequest }}
+{{- end }}
+{{- if .CoAuthors }}
+
+{{ range .CoAuthors }}Co-authored-by: {{ .Login }} <{{ .Email }}>
+{{ end }}
+{{- end }}`
+
+// commitMessage is the data the commit message template is rendered with
+type commitMessage struct {
+	// Summary line, e.g. Deploying project/service.foo
+	Summary string
+
+	// Body lines with details such as owners and freeze overrides
+	Body string
+
+	Operation string
+	Project   string
+	Services  []string
+
+	// PullRequest url the deployed code was merged in
+	PullRequest string
+
+	// CoAuthors are the people involved in the change
+	CoAuthors []*destination.CoAuthor
+
+	// Owners of the services changed, for templates to mention in the
+	// message, and so in the description of a pull request made from it
+	Owners []string
+}
+
+// addNote adds a line to the message body
+func (m *commitMessage) addNote(format string, args ...interface{}) {
+	if m.Body != "" {
+		m.Body += "\n"
+	}
+
+	m.Body += fmt.Sprintf(format, args...)
+}
+
+// addOwners notes who owns the service, and who acted outside of the owners
+// if anyone did
+func (m *commitMessage) addOwners(service string, owners []string, breakGlass string) {
+	if len(owners) > 0 {
+		m.addNote("Owners of %s: %s", service, strings.Join(owners, ", "))
+	}
+
+	for _, o := range owners {
+		if !m.hasOwner(o) {
+			m.Owners = append(m.Owners, o)
+		}
+	}
+
+	if breakGlass != "" {
+		m.addNote("Break glass: %s", breakGlass)
+	}
+}
+
+func (m *commitMessage) hasOwner(owner string) bool {
+	for _, o := range m.Owners {
+		if o == owner {
+			return true
+		}
+	}
+
+	return false
+}
+
+// commit the worktree as the configured author, signing it if there is a
+// signer
+func (s *Gitops) commit(ctx context.Context, wt Worktree, m *commitMessage) (string, error) {
+	if m.Project == "" {
+		m.Project = s.projName
+	}
+
+	msg, err := s.renderMessage(m)
+	if err != nil {
+		return "", err
+	}
+
+	c := &Commit{Message: msg, Signer: s.signer}
+
+	if s.author != nil {
+		c.Author, err = s.author(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get commit author: %w", err)
+		}
+	}
+
+	return wt.Commit(ctx, c)
+}
+
+func (s *Gitops) renderMessage(m *commitMessage) (string, error) {
+	text := s.messageTemplate
+	if text == "" {
+		text = defaultCommitMessage
+	}
+
+	tmpl, err := template.New("commit").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse commit message template: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, m); err != nil {
+		return "", fmt.Errorf("failed to render commit message: %w", err)
+	}
+
+	return strings.TrimSpace(buf.String()) + "\n", nil
+}
@@ -0,0 +1,23 @@
+package gitops
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddOwners(t *testing.T) {
+	m := &commitMessage{}
+
+	m.addOwners("api", []string{"alice", "org/team"}, "")
+	m.addOwners("web", []string{"org/team", "bob"}, "carol")
+	m.addOwners("worker", nil, "")
+
+	wantBody := "Owners of api: alice, org/team\nOwners of web: org/team, bob\nBreak glass: carol"
+	if m.Body != wantBody {
+		t.Errorf("got body %q, want %q", m.Body, wantBody)
+	}
+
+	if want := []string{"alice", "org/team", "bob"}; !reflect.DeepEqual(m.Owners, want) {
+		t.Errorf("got owners %v, want %v", m.Owners, want)
+	}
+}
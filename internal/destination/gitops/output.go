@@ -0,0 +1,26 @@
+package gitops
+
+import (
+	"fmt"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+)
+
+// Output modes of the manifests
+const (
+	OutputPlain     = "plain"
+	OutputKustomize = "kustomize"
+)
+
+// writeOutput adds what the output mode needs to the manifests after the
+// services changed
+func (s *Gitops) writeOutput(manifestRoot string, services ...string) error {
+	switch s.output {
+	case "", OutputPlain:
+		return nil
+	case OutputKustomize:
+		return destination.WriteKustomizations(manifestRoot, services)
+	default:
+		return fmt.Errorf("unknown output mode '%s'", s.output)
+	}
+}
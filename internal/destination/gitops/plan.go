@@ -0,0 +1,62 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+)
+
+// PlanDeploy renders the deploy and checks it against the policies, and
+// diffs its config, from a single checkout of the gitops repo. Services
+// without a version are planned at their next deploy version.
+func (s *Gitops) PlanDeploy(ctx context.Context, p *destination.DeployParams) (*destination.DeployPlan, error) {
+	if p.ProjectName != s.projName {
+		return nil, fmt.Errorf("project %s not supported", p.ProjectName)
+	}
+
+	paths := []string{s.bundlePath}
+	if s.localDirs == nil {
+		paths = s.checkoutPaths(s.bundlePath, s.templatePath)
+	}
+
+	wt, err := s.repo.Checkout(ctx, paths...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkout gitops repo: %w", err)
+	}
+
+	//nolint:errcheck
+	defer wt.Close()
+
+	dest, err := s.load(wt.Root())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sp := range p.Services {
+		if sp.Version != "" {
+			continue
+		}
+
+		if sp.Version, err = dest.NextDeployVersion(s.projName, sp.Config.Name); err != nil {
+			return nil, fmt.Errorf("failed to get next deploy version for %s: %w", sp.Config.Name, err)
+		}
+	}
+
+	dirs := s.localDirs
+	if dirs == nil {
+		dirs = s.repoDirs(wt)
+	}
+
+	_, results, err := s.renderServices(ctx, dirs, p.Services)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check policies: %w", err)
+	}
+
+	changes, err := diffConfig(dest, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff config: %w", err)
+	}
+
+	return &destination.DeployPlan{Destination: dest, Policy: results, Config: changes}, nil
+}
@@ -0,0 +1,120 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"github.com/cygnetdigital/shipper/internal/policy"
+)
+
+// Release to the destination
+func (s *Gitops) Release(ctx context.Context, p *destination.ReleaseParams) (*destination.ReleaseResp, error) {
+	if p.Project != s.projName {
+		return nil, fmt.Errorf("project %s not supported", p.Project)
+	}
+
+	wt, err := s.repo.Checkout(ctx, lockPaths(p.LockToken, s.checkoutPaths(s.bundlePath))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to checkout gitops repo: %w", err)
+	}
+
+	//nolint:errcheck
+	defer wt.Close()
+
+	rootPath := wt.Root()
+
+	svcs, err := destination.LoadProjectServices(path.Join(rootPath, s.bundlePath), s.projName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load k8s manifests: %w", err)
+	}
+
+	svc := svcs.LookupByProjectAndName(p.Project, p.Service)
+	if svc == nil {
+		return nil, fmt.Errorf("service not found")
+	}
+
+	if !svc.HasVersion(p.Version) {
+		return nil, fmt.Errorf("version %s not found", p.Version)
+	}
+
+	if svc.CurrentReleaseVersion == p.Version {
+		return nil, fmt.Errorf("version %s is already active", p.Version)
+	}
+
+	slug, err := destination.SlugifyServiceName(svc.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to slugify service name: %w", err)
+	}
+
+	args := &destination.ReleaseContext{
+		Project:         svc.Project,
+		Name:            svc.Name,
+		Version:         p.Version,
+		SlugName:        slug,
+		SlugNameVersion: fmt.Sprintf("%s-%s", slug, p.Version),
+		Namespace:       s.namespace,
+	}
+
+	manifestRoot := path.Join(rootPath, s.bundlePath)
+
+	files, err := destination.RenderReleaseBundle(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render bundle: %w", err)
+	}
+
+	v, err := s.validator(s.CRDDir(rootPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schemas: %w", err)
+	}
+
+	if err := validateFiles(v, svc.Name, files); err != nil {
+		return nil, err
+	}
+
+	policies, err := policy.Load(path.Join(rootPath, policy.Dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policies: %w", err)
+	}
+
+	results, err := s.checkPolicies(policies, svc.Name, svc.Name, files)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.Denied(results) {
+		return nil, &policy.DeniedError{Results: results}
+	}
+
+	if err := destination.WriteReleaseBundle(manifestRoot, args, files); err != nil {
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	if err := s.writeOutput(manifestRoot, svc.Name); err != nil {
+		return nil, fmt.Errorf("failed to write %s output: %w", s.output, err)
+	}
+
+	if err := s.checkLock(wt, p.LockToken); err != nil {
+		return nil, err
+	}
+
+	msg := &commitMessage{
+		Summary:   fmt.Sprintf("Releasing %s/%s/%s", p.Project, p.Service, p.Version),
+		Operation: "release",
+		Services:  []string{p.Service},
+	}
+
+	if p.FreezeOverride != "" {
+		msg.addNote("Freeze overridden: %s", p.FreezeOverride)
+	}
+
+	msg.addOwners(p.Service, p.Owners, p.BreakGlass)
+
+	hash, err := s.commit(ctx, wt, msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+
+	return &destination.ReleaseResp{Hash: hash}, nil
+}
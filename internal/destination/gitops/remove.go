@@ -1,29 +1,30 @@
-package github
+package gitops
 
 import (
 	"context"
 	"fmt"
-	"os"
 	"path"
 
 	"github.com/cygnetdigital/shipper/internal/destination"
 )
 
 // Remove from the destination
-func (s *Github) Remove(ctx context.Context, p *destination.RemoveParams) (*destination.RemoveResp, error) {
+func (s *Gitops) Remove(ctx context.Context, p *destination.RemoveParams) (*destination.RemoveResp, error) {
 	if p.Project != s.projName {
 		return nil, fmt.Errorf("project %s not supported", p.Project)
 	}
 
-	repo, rootPath, err := s.clone()
+	wt, err := s.repo.Checkout(ctx, lockPaths(p.LockToken, []string{s.bundlePath})...)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to checkout gitops repo: %w", err)
 	}
 
 	//nolint:errcheck
-	defer os.RemoveAll(rootPath)
+	defer wt.Close()
+
+	rootPath := wt.Root()
 
-	svcs, err := destination.LoadServices(path.Join(rootPath, s.bundlePath))
+	svcs, err := destination.LoadProjectServices(path.Join(rootPath, s.bundlePath), s.projName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load k8s manifests: %w", err)
 	}
@@ -47,9 +48,23 @@ func (s *Github) Remove(ctx context.Context, p *destination.RemoveParams) (*dest
 		return nil, fmt.Errorf("failed to delete deploy: %w", err)
 	}
 
-	msg := fmt.Sprintf("Removing %s/%s/%s", p.Project, p.Service, p.Version)
+	if err := s.writeOutput(manifestRoot, svc.Name); err != nil {
+		return nil, fmt.Errorf("failed to write %s output: %w", s.output, err)
+	}
+
+	if err := s.checkLock(wt, p.LockToken); err != nil {
+		return nil, err
+	}
+
+	msg := &commitMessage{
+		Summary:   fmt.Sprintf("Removing %s/%s/%s", p.Project, p.Service, p.Version),
+		Operation: "remove",
+		Services:  []string{p.Service},
+	}
+
+	msg.addOwners(p.Service, p.Owners, p.BreakGlass)
 
-	hash, err := s.commit(msg, repo)
+	hash, err := s.commit(ctx, wt, msg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to commit: %w", err)
 	}
@@ -0,0 +1,220 @@
+package gitops
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"github.com/cygnetdigital/shipper/internal/policy"
+)
+
+// LocalDirs are local copies of the gitops repo dirs, to render and check
+// deploys with instead of checking out the gitops repo. Empty dirs are
+// skipped.
+type LocalDirs struct {
+	Templates string
+	CRDs      string
+	Policies  string
+	Secrets   string
+
+	// SealingCert is the sealed secrets cert file
+	SealingCert string
+
+	// Revision of the gitops repo the dirs were checked out at, which
+	// vendored charts are installed from. Empty for local copies.
+	Revision string
+}
+
+// SetLocalDirs to render and check deploys with. Deploys themselves always
+// use the gitops repo.
+func (s *Gitops) SetLocalDirs(dirs *LocalDirs) {
+	s.localDirs = dirs
+}
+
+// repoDirs are the dirs in a checkout of the gitops repo
+func (s *Gitops) repoDirs(wt Worktree) *LocalDirs {
+	root := wt.Root()
+
+	dirs := &LocalDirs{
+		Templates: path.Join(root, s.templatePath),
+		CRDs:      s.CRDDir(root),
+		Policies:  path.Join(root, policy.Dir),
+		Revision:  wt.Base(),
+	}
+
+	if s.secrets.Path != "" {
+		dirs.Secrets = path.Join(root, s.secrets.Path)
+	}
+
+	if s.secrets.SealedSecretsCert != "" {
+		dirs.SealingCert = path.Join(root, s.secrets.SealedSecretsCert)
+	}
+
+	return dirs
+}
+
+// renderedService is a deploy bundle rendered for a service
+type renderedService struct {
+	args  *destination.DeployContext
+	files []*destination.RenderedFile
+}
+
+// renderServices renders the deploy bundles of the services, validates them
+// and checks them against the policies
+func (s *Gitops) renderServices(ctx context.Context, dirs *LocalDirs, sps []*destination.ServiceDeployParams) ([]*renderedService, []*policy.Result, error) {
+	v, err := s.validator(dirs.CRDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load schemas: %w", err)
+	}
+
+	policies, err := policy.Load(dirs.Policies)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load policies: %w", err)
+	}
+
+	secretNames, err := s.secretNames(dirs.Secrets)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out := []*renderedService{}
+	results := []*policy.Result{}
+
+	for _, sp := range sps {
+		args, err := s.NewDeployContext(ctx, dirs.Templates, sp)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := s.checkSecretRefs(secretNames, args); err != nil {
+			return nil, nil, err
+		}
+
+		files, err := s.renderBundle(ctx, dirs, sp, args)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to render bundle: %w", err)
+		}
+
+		dir := path.Join(sp.Config.Name, sp.Version)
+
+		if err := validateFiles(v, dir, files); err != nil {
+			return nil, nil, err
+		}
+
+		res, err := s.checkPolicies(policies, sp.Config.Name, dir, files)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		results = append(results, res...)
+		out = append(out, &renderedService{args: args, files: files})
+	}
+
+	return out, results, nil
+}
+
+// checkPolicies against the files rendered into dir for the service
+func (s *Gitops) checkPolicies(policies *policy.Set, service, dir string, files []*destination.RenderedFile) ([]*policy.Result, error) {
+	results := []*policy.Result{}
+
+	for _, f := range files {
+		manifests, err := destination.ParseManifests(f.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", f.Name, err)
+		}
+
+		in := &policy.Input{
+			File:      path.Join(dir, f.Name),
+			Project:   s.projName,
+			Service:   service,
+			Namespace: s.namespace,
+		}
+
+		for _, m := range manifests {
+			res, err := policies.Evaluate(in, m.Raw)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check %s: %w", in.File, err)
+			}
+
+			results = append(results, res...)
+		}
+	}
+
+	return results, nil
+}
+
+// withDirs calls fn with the local dirs, or the dirs of a checkout of the
+// gitops repo
+func (s *Gitops) withDirs(ctx context.Context, fn func(dirs *LocalDirs) error) error {
+	if s.localDirs != nil {
+		return fn(s.localDirs)
+	}
+
+	wt, err := s.repo.Checkout(ctx, s.checkoutPaths(s.templatePath)...)
+	if err != nil {
+		return fmt.Errorf("failed to checkout gitops repo: %w", err)
+	}
+
+	//nolint:errcheck
+	defer wt.Close()
+
+	return fn(s.repoDirs(wt))
+}
+
+// RenderDeploy renders the service's deploy bundle without writing it out
+func (s *Gitops) RenderDeploy(ctx context.Context, sp *destination.ServiceDeployParams) ([]*destination.RenderedFile, error) {
+	var files []*destination.RenderedFile
+
+	err := s.withDirs(ctx, func(dirs *LocalDirs) error {
+		dirs = &LocalDirs{Templates: dirs.Templates, CRDs: dirs.CRDs, Secrets: dirs.Secrets, SealingCert: dirs.SealingCert, Revision: dirs.Revision}
+
+		bundles, _, err := s.renderServices(ctx, dirs, []*destination.ServiceDeployParams{sp})
+		if err != nil {
+			return err
+		}
+
+		files = bundles[0].files
+
+		return nil
+	})
+
+	return files, err
+}
+
+// CheckParams validates the params the services give their templates,
+// without rendering them
+func (s *Gitops) CheckParams(ctx context.Context, p *destination.DeployParams) error {
+	if p.ProjectName != s.projName {
+		return fmt.Errorf("project %s not supported", p.ProjectName)
+	}
+
+	return s.withDirs(ctx, func(dirs *LocalDirs) error {
+		for _, sp := range p.Services {
+			if _, err := resolveParams(dirs.Templates, sp); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// CheckDeploy renders the deploy bundles and returns the policies they
+// break, without deploying them
+func (s *Gitops) CheckDeploy(ctx context.Context, p *destination.DeployParams) ([]*policy.Result, error) {
+	if p.ProjectName != s.projName {
+		return nil, fmt.Errorf("project %s not supported", p.ProjectName)
+	}
+
+	var results []*policy.Result
+
+	err := s.withDirs(ctx, func(dirs *LocalDirs) error {
+		var err error
+		_, results, err = s.renderServices(ctx, dirs, p.Services)
+
+		return err
+	})
+
+	return results, err
+}
@@ -0,0 +1,344 @@
+package gitops
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/cygnetdigital/shipper/internal/gitcache"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// Repository is a gitops repository which can be checked out and committed to
+type Repository interface {
+	// Checkout the latest state of the repository. Only the files under the
+	// given paths are written to the worktree. Paths naming a single file
+	// check it out to be read, and changes to it aren't committed.
+	Checkout(ctx context.Context, paths ...string) (Worktree, error)
+}
+
+// Worktree is a checkout of a repository on the local filesystem
+type Worktree interface {
+	// Root directory of the checkout
+	Root() string
+
+	// Base is the hash of the commit that was checked out, which commits
+	// from the worktree follow
+	Base() string
+
+	// Commit all changes made under the checked out paths and push them,
+	// returning the hash of the new commit
+	Commit(ctx context.Context, c *Commit) (string, error)
+
+	// Close removes the checkout
+	Close() error
+}
+
+// Commit describes the commit to make from a worktree
+type Commit struct {
+	Message string
+
+	// Author of the commit. The git config is used if nil.
+	Author *object.Signature
+
+	// Signer signs the commit, if set
+	Signer Signer
+}
+
+// AuthFunc provides the auth to use for each git operation
+type AuthFunc func() (transport.AuthMethod, error)
+
+// Remote is a repository that is cloned over https or ssh and pushed back to
+type Remote struct {
+	url    string
+	branch string
+	auth   AuthFunc
+	cache  *gitcache.Cache
+}
+
+// NewRemote sets up a remote repository. Branch may be empty to use the
+// default branch of the remote. The cache may be nil to clone the repository
+// from scratch every time.
+func NewRemote(repoURL, branch string, auth AuthFunc, cache *gitcache.Cache) *Remote {
+	return &Remote{
+		url:    NormalizeURL(repoURL),
+		branch: branch,
+		auth:   auth,
+		cache:  cache,
+	}
+}
+
+var scpLikeURL = regexp.MustCompile(`^[\w.\-]+@[\w.\-]+:`)
+
+// NormalizeURL adds the https scheme to urls like 'github.com/org/repo'.
+// ssh urls, including the scp like 'git@host:org/repo.git', are left as is.
+func NormalizeURL(repoURL string) string {
+	if scpLikeURL.MatchString(repoURL) {
+		return repoURL
+	}
+
+	uri, err := url.Parse(repoURL)
+	if err != nil {
+		return repoURL
+	}
+
+	if uri.Scheme == "" {
+		uri.Scheme = "https"
+	}
+
+	return uri.String()
+}
+
+// IsSSH returns true if the url should be accessed over ssh
+func IsSSH(repoURL string) bool {
+	if scpLikeURL.MatchString(repoURL) {
+		return true
+	}
+
+	uri, err := url.Parse(repoURL)
+
+	return err == nil && uri.Scheme == "ssh"
+}
+
+// Checkout fetches the repository, using the cached mirror if there is a
+// cache, and writes only the files under paths into a temporary directory.
+func (r *Remote) Checkout(ctx context.Context, paths ...string) (Worktree, error) {
+	auth, err := r.auth()
+	if err != nil {
+		return nil, err
+	}
+
+	repo, branch, err := r.open(ctx, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find branch %s: %w", branch, err)
+	}
+
+	base, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", ref.Hash(), err)
+	}
+
+	tree, err := base.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	temp, err := os.MkdirTemp("", "dir")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range paths {
+		if err := checkoutPath(tree, temp, p); err != nil {
+			//nolint:errcheck
+			os.RemoveAll(temp)
+
+			return nil, fmt.Errorf("failed to checkout %s: %w", p, err)
+		}
+	}
+
+	return &remoteWorktree{
+		remote: r,
+		repo:   repo,
+		root:   temp,
+		branch: branch,
+		base:   base,
+		paths:  paths,
+	}, nil
+}
+
+// open the repository without a worktree, returning the branch to use
+func (r *Remote) open(ctx context.Context, auth transport.AuthMethod) (*git.Repository, string, error) {
+	if r.cache != nil {
+		mirror, err := r.cache.Mirror(ctx, r.url, auth)
+		if err != nil {
+			return nil, "", err
+		}
+
+		branch := r.branch
+
+		if branch == "" {
+			branch, err = mirror.DefaultBranch(ctx, auth)
+			if err != nil {
+				return nil, "", err
+			}
+		}
+
+		repo, err := mirror.Repository()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open mirror: %w", err)
+		}
+
+		return repo, branch, nil
+	}
+
+	opts := &git.CloneOptions{
+		URL:        r.url,
+		Depth:      1,
+		Auth:       auth,
+		NoCheckout: true,
+	}
+
+	if r.branch != "" {
+		opts.ReferenceName = plumbing.NewBranchReferenceName(r.branch)
+		opts.SingleBranch = true
+	}
+
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, opts)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to clone repo: %w", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	return repo, head.Name().Short(), nil
+}
+
+type remoteWorktree struct {
+	remote *Remote
+	repo   *git.Repository
+	root   string
+	branch string
+
+	// commit that was checked out, and the paths written from it
+	base  *object.Commit
+	paths []string
+}
+
+func (w *remoteWorktree) Root() string {
+	return w.root
+}
+
+func (w *remoteWorktree) Base() string {
+	return w.base.Hash.String()
+}
+
+// Commit builds a tree from the base commit with each checked out path
+// replaced by the files on disk, then commits and pushes it.
+func (w *remoteWorktree) Commit(ctx context.Context, c *Commit) (string, error) {
+	st := w.repo.Storer
+
+	baseTree, err := w.base.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	treeHash := w.base.TreeHash
+
+	for _, p := range w.paths {
+		if _, err := baseTree.File(path.Clean(p)); err == nil {
+			continue
+		}
+
+		orig, _ := baseTree.Tree(path.Clean(p))
+
+		sub, err := writeDirTree(st, filepath.Join(w.root, filepath.FromSlash(p)), orig)
+		if err != nil {
+			return "", fmt.Errorf("failed to write tree for %s: %w", p, err)
+		}
+
+		treeHash, err = replaceTree(st, treeHash, p, sub)
+		if err != nil {
+			return "", fmt.Errorf("failed to write tree for %s: %w", p, err)
+		}
+	}
+
+	if treeHash == w.base.TreeHash {
+		return "", fmt.Errorf("no changes to commit")
+	}
+
+	author, err := w.author(c)
+	if err != nil {
+		return "", err
+	}
+
+	commit := &object.Commit{
+		Author:       *author,
+		Committer:    *author,
+		Message:      c.Message,
+		TreeHash:     treeHash,
+		ParentHashes: []plumbing.Hash{w.base.Hash},
+	}
+
+	if c.Signer != nil {
+		if err := SignCommit(c.Signer, commit); err != nil {
+			return "", err
+		}
+	}
+
+	obj := st.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return "", fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	hash, err := st.SetEncodedObject(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to store commit: %w", err)
+	}
+
+	ref := plumbing.NewBranchReferenceName(w.branch)
+
+	if err := st.SetReference(plumbing.NewHashReference(ref, hash)); err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", w.branch, err)
+	}
+
+	auth, err := w.remote.auth()
+	if err != nil {
+		return "", err
+	}
+
+	err = w.repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: "origin",
+		Auth:       auth,
+		RefSpecs:   []config.RefSpec{config.RefSpec(ref + ":" + ref)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to push: %w", err)
+	}
+
+	return hash.String(), nil
+}
+
+// author of the commit, falling back to the git config and then to shipper
+// itself, as there may be no git config where shipper runs in CI
+func (w *remoteWorktree) author(c *Commit) (*object.Signature, error) {
+	if c.Author != nil {
+		return c.Author, nil
+	}
+
+	opts := &git.CommitOptions{}
+
+	err := opts.Validate(w.repo)
+	if errors.Is(err, git.ErrMissingAuthor) {
+		return &object.Signature{Name: "shipper", Email: "shipper@localhost", When: time.Now()}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit author: %w", err)
+	}
+
+	return opts.Author, nil
+}
+
+func (w *remoteWorktree) Close() error {
+	return os.RemoveAll(w.root)
+}
@@ -0,0 +1,223 @@
+package gitops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/conf"
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"github.com/cygnetdigital/shipper/internal/secrets"
+)
+
+// SetAgeKeyFile to decrypt sealed secret values with
+func (s *Gitops) SetAgeKeyFile(file string) {
+	s.ageKeyFile = file
+}
+
+// resolveSecrets names the service's secrets for the deploy, and returns the
+// secret variables and mounts of its config with references to those secrets
+// resolved to their names in the deploy
+func resolveSecrets(slugNameVersion string, deploy *conf.ServiceDeploy) (map[string]string, []*destination.SecretVariable, []*conf.SecretMount, error) {
+	names := map[string]string{}
+
+	for _, sec := range deploy.Secrets {
+		if sec.Name == "" {
+			return nil, nil, nil, fmt.Errorf("secrets need a name")
+		}
+
+		if _, ok := names[sec.Name]; ok {
+			return nil, nil, nil, fmt.Errorf("secret %s is declared more than once", sec.Name)
+		}
+
+		if (len(sec.Sealed) > 0) == (len(sec.External) > 0) {
+			return nil, nil, nil, fmt.Errorf("secret %s must have either sealed or external values", sec.Name)
+		}
+
+		names[sec.Name] = destination.SecretName(slugNameVersion, sec.Name)
+	}
+
+	resolve := func(name string) string {
+		if n, ok := names[name]; ok {
+			return n
+		}
+
+		return name
+	}
+
+	variables := []*destination.SecretVariable{}
+
+	for _, c := range deploy.Config {
+		for k, src := range c.ValueFrom {
+			if src == nil || src.SecretKeyRef == nil || src.SecretKeyRef.Name == "" || src.SecretKeyRef.Key == "" {
+				return nil, nil, nil, fmt.Errorf("config %s.%s needs a secretKeyRef with a name and key", c.Name, k)
+			}
+
+			variables = append(variables, &destination.SecretVariable{
+				Name:       destination.ConfigKey(c.Name, k),
+				SecretName: resolve(src.SecretKeyRef.Name),
+				Key:        src.SecretKeyRef.Key,
+			})
+		}
+	}
+
+	sort.Slice(variables, func(i, j int) bool { return variables[i].Name < variables[j].Name })
+
+	mounts := []*conf.SecretMount{}
+
+	for _, m := range deploy.SecretMounts {
+		mounts = append(mounts, &conf.SecretMount{
+			MountName:  m.MountName,
+			SecretName: resolve(m.SecretName),
+			MountPath:  m.MountPath,
+		})
+	}
+
+	return names, variables, mounts, nil
+}
+
+// addSecrets renders the service's secrets into the bundle, sealing sealed
+// values with the cert in dirs
+func (s *Gitops) addSecrets(dirs *LocalDirs, svc *shipper.Service, args *destination.DeployContext, files []*destination.RenderedFile) ([]*destination.RenderedFile, error) {
+	objs := []any{}
+
+	var (
+		decrypter *secrets.Decrypter
+		sealer    *secrets.Sealer
+		sops      map[string]map[string]string
+	)
+
+	for _, sec := range svc.Deploy.Secrets {
+		name := args.Secrets[sec.Name]
+
+		if len(sec.External) > 0 {
+			if s.secrets.Store == "" {
+				return nil, fmt.Errorf("the project has no external secrets store for secret %s", sec.Name)
+			}
+
+			objs = append(objs, destination.ExternalSecret(args, name, s.secrets.Store, s.secrets.StoreKind, sec.External))
+
+			continue
+		}
+
+		if decrypter == nil {
+			var err error
+
+			if decrypter, err = secrets.NewDecrypter(s.ageKeyFile); err != nil {
+				return nil, err
+			}
+
+			if sealer, err = secrets.NewSealer(dirs.SealingCert); err != nil {
+				return nil, err
+			}
+		}
+
+		sealed := map[string]string{}
+
+		for k, v := range sec.Sealed {
+			var (
+				plain []byte
+				err   error
+			)
+
+			if secrets.IsSops(v) {
+				if sops == nil {
+					if sops, err = sopsSealed(decrypter, svc); err != nil {
+						return nil, err
+					}
+				}
+
+				plain = []byte(sops[sec.Name][k])
+			} else if plain, err = decrypter.Decrypt(v); err != nil {
+				return nil, fmt.Errorf("failed to decrypt %s of secret %s: %w", k, sec.Name, err)
+			}
+
+			if sealed[k], err = sealer.Seal(args.Namespace, name, plain); err != nil {
+				return nil, fmt.Errorf("failed to seal %s of secret %s: %w", k, sec.Name, err)
+			}
+		}
+
+		objs = append(objs, destination.SealedSecret(args, name, sealed))
+	}
+
+	return destination.AddSecrets(args, objs, files)
+}
+
+// sopsSealed decrypts the service's sops encrypted config, returning the
+// plaintext sealed values of its secrets by name
+func sopsSealed(decrypter *secrets.Decrypter, svc *shipper.Service) (map[string]map[string]string, error) {
+	doc, err := decrypter.DecryptSops(svc.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt config of %s: %w", svc.Name, err)
+	}
+
+	plain := conf.Service{}
+	if err := doc.Decode(&plain); err != nil {
+		return nil, fmt.Errorf("failed to decode decrypted config of %s: %w", svc.Name, err)
+	}
+
+	sealed := map[string]map[string]string{}
+	for _, sec := range plain.Deploy.Secrets {
+		sealed[sec.Name] = sec.Sealed
+	}
+
+	return sealed, nil
+}
+
+// secretNames are the secrets in the secrets dir, or nil if references to
+// them aren't checked
+func (s *Gitops) secretNames(dir string) (map[string]bool, error) {
+	if s.skipSecretCheck || dir == "" {
+		return nil, nil
+	}
+
+	names, err := secrets.Names(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secrets: %w", err)
+	}
+
+	return names, nil
+}
+
+// checkSecretRefs fails if the deploy references secrets which aren't in
+// the deploy's namespace in the gitops repo, or rendered with it
+func (s *Gitops) checkSecretRefs(known map[string]bool, args *destination.DeployContext) error {
+	if known == nil {
+		return nil
+	}
+
+	own := map[string]bool{}
+	for _, name := range args.Secrets {
+		own[name] = true
+	}
+
+	refs := []string{}
+	for _, m := range args.SecretMounts {
+		refs = append(refs, m.SecretName)
+	}
+
+	for _, v := range args.SecretVariables {
+		refs = append(refs, v.SecretName)
+	}
+
+	missing := []string{}
+	seen := map[string]bool{}
+
+	for _, name := range refs {
+		inRepo := known[secrets.SecretKey(args.Namespace, name)] || known[secrets.SecretKey("", name)]
+
+		if !inRepo && !own[name] && !seen[name] {
+			seen[name] = true
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	sort.Strings(missing)
+
+	return fmt.Errorf("secrets used by %s are not in namespace %s in %s: %s", args.Name, args.Namespace, s.secrets.Path, strings.Join(missing, ", "))
+}
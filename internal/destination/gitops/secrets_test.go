@@ -0,0 +1,53 @@
+package gitops
+
+import (
+	"testing"
+
+	"github.com/cygnetdigital/shipper/internal/conf"
+	"github.com/cygnetdigital/shipper/internal/destination"
+)
+
+func TestCheckSecretRefs(t *testing.T) {
+	known := map[string]bool{
+		"prod/db":     true,
+		"staging/api": true,
+		"/shared":     true,
+	}
+
+	tests := []struct {
+		name      string
+		namespace string
+		refs      []string
+		own       map[string]string
+		wantErr   bool
+	}{
+		{name: "in the namespace", namespace: "prod", refs: []string{"db"}},
+		{name: "without a namespace", namespace: "prod", refs: []string{"shared"}},
+		{name: "rendered with the deploy", namespace: "prod", refs: []string{"s-api-v1-token"}, own: map[string]string{"token": "s-api-v1-token"}},
+		{name: "in another namespace", namespace: "prod", refs: []string{"api"}, wantErr: true},
+		{name: "missing", namespace: "prod", refs: []string{"db", "nope"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := &destination.DeployContext{Namespace: tt.namespace, Secrets: tt.own}
+
+			for i, ref := range tt.refs {
+				if i%2 == 0 {
+					args.SecretMounts = append(args.SecretMounts, &conf.SecretMount{SecretName: ref})
+				} else {
+					args.SecretVariables = append(args.SecretVariables, &destination.SecretVariable{SecretName: ref})
+				}
+			}
+
+			err := (&Gitops{}).checkSecretRefs(known, args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if err := (&Gitops{}).checkSecretRefs(nil, &destination.DeployContext{SecretMounts: []*conf.SecretMount{{SecretName: "nope"}}}); err != nil {
+		t.Errorf("skipped check failed: %v", err)
+	}
+}
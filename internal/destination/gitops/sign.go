@@ -0,0 +1,128 @@
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/cygnetdigital/shipper/internal/sshsig"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+// Signer signs commits
+type Signer interface {
+	// Sign returns an armored detached signature of the message
+	Sign(message io.Reader) (string, error)
+}
+
+// SignCommit signs the commit, setting its signature
+func SignCommit(signer Signer, c *object.Commit) error {
+	obj := &plumbing.MemoryObject{}
+	if err := c.EncodeWithoutSignature(obj); err != nil {
+		return fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	r, err := obj.Reader()
+	if err != nil {
+		return err
+	}
+
+	sig, err := signer.Sign(r)
+	if err != nil {
+		return fmt.Errorf("failed to sign commit: %w", err)
+	}
+
+	c.PGPSignature = sig
+
+	return nil
+}
+
+// GPGSigner signs commits with an openpgp key
+type GPGSigner struct {
+	entity *openpgp.Entity
+}
+
+// NewGPGSigner loads the first key of an armored private key file
+func NewGPGSigner(keyFile, passphrase string) (*GPGSigner, error) {
+	f, err := os.Open(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open signing key: %w", err)
+	}
+
+	defer f.Close()
+
+	keys, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	if len(keys) == 0 || keys[0].PrivateKey == nil {
+		return nil, fmt.Errorf("no private key found in %s", keyFile)
+	}
+
+	entity := keys[0]
+
+	if entity.PrivateKey.Encrypted {
+		if err := entity.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt signing key: %w", err)
+		}
+	}
+
+	for _, sub := range entity.Subkeys {
+		if sub.PrivateKey != nil && sub.PrivateKey.Encrypted {
+			if err := sub.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+				return nil, fmt.Errorf("failed to decrypt signing subkey: %w", err)
+			}
+		}
+	}
+
+	return &GPGSigner{entity: entity}, nil
+}
+
+// Sign the message
+func (g *GPGSigner) Sign(message io.Reader) (string, error) {
+	buf := &bytes.Buffer{}
+
+	if err := openpgp.ArmoredDetachSign(buf, g.entity, message, nil); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// SSHSigner signs commits with an ssh key, in the format used by git's
+// gpg.format=ssh
+type SSHSigner struct {
+	signer ssh.Signer
+}
+
+// NewSSHSigner loads an ssh private key file
+func NewSSHSigner(keyFile, passphrase string) (*SSHSigner, error) {
+	bts, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	var signer ssh.Signer
+
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(bts, []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(bts)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+
+	return &SSHSigner{signer: signer}, nil
+}
+
+// Sign the message, see PROTOCOL.sshsig in openssh
+func (s *SSHSigner) Sign(message io.Reader) (string, error) {
+	return sshsig.Sign(s.signer, message)
+}
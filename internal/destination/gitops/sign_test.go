@@ -0,0 +1,235 @@
+package gitops
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	"github.com/cygnetdigital/shipper/internal/sshsig"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+)
+
+func writeTestFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	p := filepath.Join(t.TempDir(), name)
+
+	if err := os.WriteFile(p, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+func gpgKey(t *testing.T, passphrase string) (*openpgp.Entity, string) {
+	t.Helper()
+
+	entity, err := openpgp.NewEntity("shipper", "", "shipper@example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// self sign the identities before the keys are encrypted
+	if err := entity.SerializePrivate(io.Discard, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if passphrase != "" {
+		if err := entity.PrivateKey.Encrypt([]byte(passphrase)); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, sub := range entity.Subkeys {
+			if err := sub.PrivateKey.Encrypt([]byte(passphrase)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	buf := &bytes.Buffer{}
+
+	w, err := armor.Encode(buf, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := entity.SerializePrivateWithoutSigning(w, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	w.Close()
+
+	return entity, writeTestFile(t, "key.asc", buf.Bytes())
+}
+
+func TestGPGSigner(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+		given      string
+		wantErr    bool
+	}{
+		{name: "unencrypted"},
+		{name: "encrypted", passphrase: "hunter2", given: "hunter2"},
+		{name: "wrong passphrase", passphrase: "hunter2", given: "hunter3", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entity, keyFile := gpgKey(t, tt.passphrase)
+
+			signer, err := NewGPGSigner(keyFile, tt.given)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("NewGPGSigner should fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewGPGSigner: %v", err)
+			}
+
+			msg := "tree abc\n\ndeploy\n"
+
+			sig, err := signer.Sign(strings.NewReader(msg))
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+
+			keyring := openpgp.EntityList{entity}
+
+			if _, err := openpgp.CheckArmoredDetachedSignature(keyring, strings.NewReader(msg), strings.NewReader(sig), nil); err != nil {
+				t.Errorf("signature doesn't verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewGPGSignerErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		keyFile func(t *testing.T) string
+	}{
+		{"missing file", func(t *testing.T) string { return filepath.Join(t.TempDir(), "missing.asc") }},
+		{"not a key", func(t *testing.T) string { return writeTestFile(t, "key.asc", []byte("nope")) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewGPGSigner(tt.keyFile(t), ""); err == nil {
+				t.Error("NewGPGSigner should fail")
+			}
+		})
+	}
+}
+
+func TestSSHSigner(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		block *pem.Block
+	}{
+		{name: "ed25519", block: &pem.Block{Type: "PRIVATE KEY", Bytes: edDER}},
+		{name: "rsa", block: &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyFile := writeTestFile(t, "id", pem.EncodeToMemory(tt.block))
+
+			signer, err := NewSSHSigner(keyFile, "")
+			if err != nil {
+				t.Fatalf("NewSSHSigner: %v", err)
+			}
+
+			msg := "tree abc\n\ndeploy\n"
+
+			armored, err := signer.Sign(strings.NewReader(msg))
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+
+			// the format itself is tested in sshsig
+			if !sshsig.Verify([]ssh.PublicKey{signer.signer.PublicKey()}, []byte(msg), armored) {
+				t.Error("signature doesn't verify")
+			}
+		})
+	}
+}
+
+type recordSigner struct {
+	message []byte
+}
+
+func (r *recordSigner) Sign(message io.Reader) (string, error) {
+	b, err := io.ReadAll(message)
+	r.message = b
+
+	return "signature", err
+}
+
+func TestSignCommit(t *testing.T) {
+	when := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	c := &object.Commit{
+		Author:    object.Signature{Name: "shipper", Email: "shipper@example.com", When: when},
+		Committer: object.Signature{Name: "shipper", Email: "shipper@example.com", When: when},
+		Message:   "deploy",
+		TreeHash:  plumbing.NewHash("4b825dc642cb6eb9a060e54bf8d69288fbee4904"),
+	}
+
+	want := &plumbing.MemoryObject{}
+	if err := c.EncodeWithoutSignature(want); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recordSigner{}
+
+	if err := SignCommit(r, c); err != nil {
+		t.Fatalf("SignCommit: %v", err)
+	}
+
+	if c.PGPSignature != "signature" {
+		t.Errorf("commit signature is %q", c.PGPSignature)
+	}
+
+	rd, err := want.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantBytes, _ := io.ReadAll(rd)
+
+	if !bytes.Equal(r.message, wantBytes) {
+		t.Errorf("signed\n%s\nwant\n%s", r.message, wantBytes)
+	}
+}
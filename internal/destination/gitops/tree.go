@@ -0,0 +1,239 @@
+package gitops
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// checkoutPath writes the files under p in the tree, or the file at p, into
+// root. Missing paths are skipped, as they will be created by the first
+// commit to them.
+func checkoutPath(tree *object.Tree, root, p string) error {
+	sub := tree
+
+	if p = path.Clean(p); p != "." {
+		if f, err := tree.File(p); err == nil {
+			return writeFile(filepath.Join(root, filepath.FromSlash(p)), f)
+		}
+
+		var err error
+
+		sub, err = tree.Tree(p)
+		if errors.Is(err, object.ErrDirectoryNotFound) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return sub.Files().ForEach(func(f *object.File) error {
+		return writeFile(filepath.Join(root, filepath.FromSlash(p), filepath.FromSlash(f.Name)), f)
+	})
+}
+
+// writeFile writes the file from the tree to fp
+func writeFile(fp string, f *object.File) error {
+	if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+		return err
+	}
+
+	r, err := f.Reader()
+	if err != nil {
+		return err
+	}
+
+	defer r.Close()
+
+	out, err := os.Create(fp)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+
+		return err
+	}
+
+	return out.Close()
+}
+
+// writeDirTree stores the files in dir as a tree, reusing the modes of the
+// original tree where files already existed. A zero hash is returned for an
+// empty or missing directory.
+func writeDirTree(s storer.EncodedObjectStorer, dir string, orig *object.Tree) (plumbing.Hash, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return plumbing.ZeroHash, nil
+		}
+
+		return plumbing.ZeroHash, err
+	}
+
+	tree := &object.Tree{}
+
+	for _, e := range entries {
+		var origEntry *object.TreeEntry
+
+		if orig != nil {
+			origEntry, _ = orig.FindEntry(e.Name())
+		}
+
+		if e.IsDir() {
+			var origSub *object.Tree
+
+			if origEntry != nil && origEntry.Mode == filemode.Dir {
+				origSub, _ = orig.Tree(e.Name())
+			}
+
+			h, err := writeDirTree(s, filepath.Join(dir, e.Name()), origSub)
+			if err != nil {
+				return plumbing.ZeroHash, err
+			}
+
+			if !h.IsZero() {
+				tree.Entries = append(tree.Entries, object.TreeEntry{Name: e.Name(), Mode: filemode.Dir, Hash: h})
+			}
+
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		h, err := writeBlob(s, content)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		mode := filemode.Regular
+		if origEntry != nil && origEntry.Mode.IsFile() {
+			mode = origEntry.Mode
+		}
+
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: e.Name(), Mode: mode, Hash: h})
+	}
+
+	if len(tree.Entries) == 0 {
+		return plumbing.ZeroHash, nil
+	}
+
+	return writeTree(s, tree)
+}
+
+// replaceTree replaces the entry at p within the tree, rewriting each parent
+// tree. A zero hash for sub removes the entry.
+func replaceTree(s storer.EncodedObjectStorer, treeHash plumbing.Hash, p string, sub plumbing.Hash) (plumbing.Hash, error) {
+	if p = path.Clean(p); p == "." {
+		return sub, nil
+	}
+
+	name, rest, _ := strings.Cut(p, "/")
+
+	tree := &object.Tree{}
+
+	if !treeHash.IsZero() {
+		var err error
+
+		tree, err = object.GetTree(s, treeHash)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+
+	entries := make([]object.TreeEntry, 0, len(tree.Entries)+1)
+	child := plumbing.ZeroHash
+
+	for _, e := range tree.Entries {
+		if e.Name == name {
+			if e.Mode == filemode.Dir {
+				child = e.Hash
+			}
+
+			continue
+		}
+
+		entries = append(entries, e)
+	}
+
+	if rest == "" {
+		child = sub
+	} else {
+		var err error
+
+		child, err = replaceTree(s, child, rest, sub)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+
+	if !child.IsZero() {
+		entries = append(entries, object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: child})
+	}
+
+	if len(entries) == 0 {
+		return plumbing.ZeroHash, nil
+	}
+
+	return writeTree(s, &object.Tree{Entries: entries})
+}
+
+func writeBlob(s storer.EncodedObjectStorer, content []byte) (plumbing.Hash, error) {
+	obj := s.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	if _, err := w.Write(content); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return s.SetEncodedObject(obj)
+}
+
+// writeTree stores the tree with entries in git order, where directories sort
+// as if their name ended with a slash
+func writeTree(s storer.EncodedObjectStorer, tree *object.Tree) (plumbing.Hash, error) {
+	sortName := func(e object.TreeEntry) string {
+		if e.Mode == filemode.Dir {
+			return e.Name + "/"
+		}
+
+		return e.Name
+	}
+
+	sort.Slice(tree.Entries, func(i, j int) bool {
+		return sortName(tree.Entries[i]) < sortName(tree.Entries[j])
+	})
+
+	obj := s.NewEncodedObject()
+
+	if err := tree.Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode tree: %w", err)
+	}
+
+	return s.SetEncodedObject(obj)
+}
@@ -0,0 +1,95 @@
+package gitops
+
+import (
+	"path"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"github.com/cygnetdigital/shipper/internal/policy"
+	"github.com/cygnetdigital/shipper/internal/schema"
+)
+
+// SetSkipValidation turns off validating rendered manifests against the
+// kubernetes schemas
+func (s *Gitops) SetSkipValidation(skip bool) {
+	s.skipValidation = skip
+}
+
+// SetSkipSecretCheck turns off checking the secrets services reference are
+// in the gitops repo
+func (s *Gitops) SetSkipSecretCheck(skip bool) {
+	s.skipSecretCheck = skip
+}
+
+// CRDDir is the dir of CRDs to validate custom resources with, in a checkout
+// of the gitops repo at root. Empty if the project doesn't have one.
+func (s *Gitops) CRDDir(root string) string {
+	if s.crdPath == "" {
+		return ""
+	}
+
+	return path.Join(root, s.crdPath)
+}
+
+// checkoutPaths are the paths to checkout, along with the policies, the
+// sealed secrets cert, the CRDs if manifests are validated and the secrets
+// if references to them are checked
+func (s *Gitops) checkoutPaths(paths ...string) []string {
+	if s.crdPath != "" && !s.skipValidation {
+		paths = append(paths, s.crdPath)
+	}
+
+	if s.secrets.Path != "" && !s.skipSecretCheck {
+		paths = append(paths, s.secrets.Path)
+	}
+
+	if s.secrets.SealedSecretsCert != "" {
+		paths = append(paths, s.secrets.SealedSecretsCert)
+	}
+
+	paths = append(paths, policy.Dir)
+
+	return paths
+}
+
+// validator for rendered manifests, or nil if validation is skipped
+func (s *Gitops) validator(crdDir string) (*schema.Validator, error) {
+	if s.skipValidation {
+		return nil, nil
+	}
+
+	v, err := schema.New()
+	if err != nil {
+		return nil, err
+	}
+
+	if crdDir != "" {
+		if err := v.AddCRDs(crdDir); err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+// validateFiles rendered into dir
+func validateFiles(v *schema.Validator, dir string, files []*destination.RenderedFile) error {
+	if v == nil {
+		return nil
+	}
+
+	problems := []schema.Problem{}
+
+	for _, f := range files {
+		if f.Generated {
+			continue
+		}
+
+		problems = append(problems, v.ValidateFile(path.Join(dir, f.Name), f.Data)...)
+	}
+
+	if len(problems) > 0 {
+		return &schema.Error{Problems: problems}
+	}
+
+	return nil
+}
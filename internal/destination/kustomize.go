@@ -0,0 +1,140 @@
+package destination
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KustomizationFile is written to each bundle, service and the manifest root
+// in the kustomize output mode
+const KustomizationFile = "kustomization.yaml"
+
+// Kustomization lists the resources of a dir
+type Kustomization struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Resources  []string `yaml:"resources"`
+}
+
+// WriteKustomizations regenerates the kustomizations of the services, each
+// of their deploy bundles, and the manifest root. A service's kustomization
+// lists its release and every deploy, and the root lists every service dir
+// with a kustomization.
+func WriteKustomizations(manifestRoot string, services []string) error {
+	for _, name := range services {
+		if err := writeServiceKustomization(filepath.Join(manifestRoot, name)); err != nil {
+			return fmt.Errorf("failed to write kustomizations for %s: %w", name, err)
+		}
+	}
+
+	entries, err := os.ReadDir(manifestRoot)
+	if err != nil {
+		return err
+	}
+
+	resources := []string{}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(manifestRoot, e.Name(), KustomizationFile)); err == nil {
+			resources = append(resources, e.Name())
+		}
+	}
+
+	return writeKustomization(manifestRoot, resources)
+}
+
+func writeServiceKustomization(serviceDir string) error {
+	entries, err := os.ReadDir(serviceDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	resources := yamlFiles(entries)
+	versions := []string{}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		bundleDir := filepath.Join(serviceDir, e.Name())
+
+		files, err := os.ReadDir(bundleDir)
+		if err != nil {
+			return err
+		}
+
+		if err := writeKustomization(bundleDir, yamlFiles(files)); err != nil {
+			return err
+		}
+
+		versions = append(versions, e.Name())
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versionLess(versions[i], versions[j]) })
+
+	return writeKustomization(serviceDir, append(resources, versions...))
+}
+
+// yamlFiles are the manifest files in the entries
+func yamlFiles(entries []os.DirEntry) []string {
+	out := []string{}
+
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == ".yaml" && e.Name() != KustomizationFile {
+			out = append(out, e.Name())
+		}
+	}
+
+	return out
+}
+
+func writeKustomization(dir string, resources []string) error {
+	k := &Kustomization{
+		APIVersion: "kustomize.config.k8s.io/v1beta1",
+		Kind:       "Kustomization",
+		Resources:  resources,
+	}
+
+	buf := &bytes.Buffer{}
+
+	enc := yaml.NewEncoder(buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(k); err != nil {
+		return err
+	}
+
+	if err := enc.Close(); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, KustomizationFile), buf.Bytes(), 0644)
+}
+
+// versionLess orders deploy versions numerically, so v10 comes after v9
+func versionLess(a, b string) bool {
+	na, errA := strconv.Atoi(strings.TrimPrefix(a, "v"))
+	nb, errB := strconv.Atoi(strings.TrimPrefix(b, "v"))
+
+	if errA != nil || errB != nil {
+		return a < b
+	}
+
+	return na < nb
+}
@@ -0,0 +1,147 @@
+package destination
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestWriteKustomizations(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    []string
+		services []string
+
+		// want are the resources of each kustomization, by its dir
+		want map[string][]string
+	}{
+		{
+			name: "versions in numeric order",
+			files: []string{
+				"api/release.yaml", "api/notes.txt",
+				"api/v9/deploy.yaml", "api/v10/deploy.yaml", "api/v10/service.yaml", "api/v2/deploy.yaml",
+			},
+			services: []string{"api"},
+			want: map[string][]string{
+				".":       {"api"},
+				"api":     {"release.yaml", "v2", "v9", "v10"},
+				"api/v2":  {"deploy.yaml"},
+				"api/v9":  {"deploy.yaml"},
+				"api/v10": {"deploy.yaml", "service.yaml"},
+			},
+		},
+		{
+			name: "stale kustomizations are replaced",
+			files: []string{
+				"api/kustomization.yaml", "api/v1/deploy.yaml", "api/v1/kustomization.yaml",
+			},
+			services: []string{"api"},
+			want: map[string][]string{
+				".":      {"api"},
+				"api":    {"v1"},
+				"api/v1": {"deploy.yaml"},
+			},
+		},
+		{
+			name: "other services are kept in the root",
+			files: []string{
+				"api/v1/deploy.yaml", "web/kustomization.yaml", "web/v3/deploy.yaml", "scratch/notes.yaml",
+			},
+			services: []string{"api"},
+			want: map[string][]string{
+				".":      {"api", "web"},
+				"api":    {"v1"},
+				"api/v1": {"deploy.yaml"},
+			},
+		},
+		{
+			name: "removed service dir",
+			files: []string{
+				"kustomization.yaml", "api/v1/deploy.yaml", "api/kustomization.yaml",
+			},
+			services: []string{"api", "web"},
+			want: map[string][]string{
+				".":      {"api"},
+				"api":    {"v1"},
+				"api/v1": {"deploy.yaml"},
+			},
+		},
+		{
+			name:     "no services",
+			services: []string{"web"},
+			want: map[string][]string{
+				".": {},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+
+			for _, f := range tt.files {
+				fp := filepath.Join(root, f)
+
+				if err := os.MkdirAll(filepath.Dir(fp), 0o755); err != nil {
+					t.Fatal(err)
+				}
+
+				// stale kustomizations list something that's gone
+				data := []byte("kind: ConfigMap\n")
+				if filepath.Base(f) == KustomizationFile {
+					data = []byte("resources: [gone]\n")
+				}
+
+				if err := os.WriteFile(fp, data, 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := WriteKustomizations(root, tt.services); err != nil {
+				t.Fatalf("WriteKustomizations: %v", err)
+			}
+
+			for dir, want := range tt.want {
+				data, err := os.ReadFile(filepath.Join(root, dir, KustomizationFile))
+				if err != nil {
+					t.Fatalf("no kustomization in %s: %v", dir, err)
+				}
+
+				k := &Kustomization{}
+				if err := yaml.Unmarshal(data, k); err != nil {
+					t.Fatalf("failed to parse kustomization in %s: %v", dir, err)
+				}
+
+				if k.APIVersion != "kustomize.config.k8s.io/v1beta1" || k.Kind != "Kustomization" {
+					t.Errorf("%s is a %s %s", dir, k.APIVersion, k.Kind)
+				}
+
+				if !reflect.DeepEqual(k.Resources, want) {
+					t.Errorf("%s has resources %v, want %v", dir, k.Resources, want)
+				}
+			}
+		})
+	}
+}
+
+func TestVersionLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"v9", "v10", true},
+		{"v10", "v9", false},
+		{"v2", "v2", false},
+		{"canary", "v2", true},
+		{"beta", "alpha", false},
+	}
+
+	for _, tt := range tests {
+		if got := versionLess(tt.a, tt.b); got != tt.want {
+			t.Errorf("versionLess(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
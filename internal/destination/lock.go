@@ -0,0 +1,57 @@
+package destination
+
+import (
+	"fmt"
+	"time"
+)
+
+// LockDir is where locks are kept in the destination, relative to its root
+const LockDir = ".shipper/locks"
+
+// Lock is an advisory lock on a project, held while an operation plans and
+// pushes changes so concurrent operations can't pick the same versions.
+// Token is random per run, so only the run which took the lock can re-take,
+// release or push under it.
+type Lock struct {
+	Project   string    `yaml:"project"`
+	Holder    string    `yaml:"holder"`
+	Token     string    `yaml:"token"`
+	Operation string    `yaml:"operation"`
+	Acquired  time.Time `yaml:"acquired"`
+	Expires   time.Time `yaml:"expires"`
+}
+
+// Expired returns true if the lock can be taken over by another run
+func (l *Lock) Expired(now time.Time) bool {
+	return !now.Before(l.Expires)
+}
+
+// LockedError is returned when the project is locked by another run
+type LockedError struct {
+	Lock *Lock
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("project %s is locked by %s for %s until %s", e.Lock.Project, e.Lock.Holder, e.Lock.Operation, e.Lock.Expires.Local().Format(time.RFC1123))
+}
+
+// LockLostError is returned when a run pushes a change after its lock on the
+// project expired or was taken over
+type LockLostError struct {
+	Project string
+
+	// Lock now on the project, nil if it isn't locked
+	Lock *Lock
+}
+
+func (e *LockLostError) Error() string {
+	if e.Lock == nil {
+		return fmt.Sprintf("lost the lock on %s, it was released", e.Project)
+	}
+
+	if e.Lock.Expired(time.Now()) {
+		return fmt.Sprintf("lost the lock on %s, it expired %s", e.Project, e.Lock.Expires.Local().Format(time.RFC1123))
+	}
+
+	return fmt.Sprintf("lost the lock on %s, it is held by %s for %s", e.Project, e.Lock.Holder, e.Lock.Operation)
+}
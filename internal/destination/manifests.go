@@ -1,6 +1,7 @@
 package destination
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,8 +9,10 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
@@ -35,6 +38,11 @@ func openAndParseManifest(filename string) ([]*Manifest, error) {
 	return mfs, nil
 }
 
+// ParseManifests parses the objects in the yaml or json data
+func ParseManifests(data []byte) ([]*Manifest, error) {
+	return parseManifest(bytes.NewReader(data))
+}
+
 func parseManifest(f io.Reader) ([]*Manifest, error) {
 	out := []*Manifest{}
 	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
@@ -56,10 +64,51 @@ func parseManifest(f io.Reader) ([]*Manifest, error) {
 	return out, nil
 }
 
+// parseFiles parses the manifest files concurrently, keeping them in order
+func parseFiles(filepaths []string) ([]*Manifest, error) {
+	results := make([][]*Manifest, len(filepaths))
+	errs := make([]error, len(filepaths))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				results[i], errs[i] = openAndParseManifest(filepaths[i])
+			}
+		}()
+	}
+
+	for i := range filepaths {
+		jobs <- i
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	out := []*Manifest{}
+
+	for i, mfs := range results {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("failed to parse file %s: %w", filepaths[i], errs[i])
+		}
+
+		out = append(out, mfs...)
+	}
+
+	return out, nil
+}
+
 func glob(root string) ([]string, error) {
 	var files []string
 	err := filepath.WalkDir(root, func(s string, d fs.DirEntry, e error) error {
-		if filepath.Ext(s) == ".yaml" {
+		// kustomizations aren't manifests
+		if filepath.Ext(s) == ".yaml" && filepath.Base(s) != KustomizationFile {
 			files = append(files, s)
 		}
 
@@ -84,7 +133,8 @@ func (m *Manifest) MarshalJSON() ([]byte, error) {
 
 // UnmarshalJSON ...
 func (m *Manifest) UnmarshalJSON(b []byte) error {
-	m.Raw = b
+	// the decoder may reuse b after we return
+	m.Raw = append([]byte(nil), b...)
 
 	type Alias Manifest
 	aux := Alias{}
@@ -0,0 +1,153 @@
+package destination
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateSpecFile declares the parameters of a deploy template. It lives in
+// the template directory and isn't written out.
+const templateSpecFile = "template.yaml"
+
+// TemplateSpec declares what a deploy template accepts
+type TemplateSpec struct {
+	Description string           `yaml:"description"`
+	Params      []*TemplateParam `yaml:"params"`
+}
+
+// TemplateParam is a single template parameter
+type TemplateParam struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+
+	// Type is one of string, int, number, bool, list or object. Defaults to
+	// string.
+	Type string `yaml:"type"`
+
+	Default  any  `yaml:"default"`
+	Required bool `yaml:"required"`
+}
+
+// LoadTemplateSpec loads the spec of the template dir. Templates without a
+// spec have no params.
+func LoadTemplateSpec(templateDir string) (*TemplateSpec, error) {
+	fp := path.Join(templateDir, templateSpecFile)
+
+	bts, err := os.ReadFile(fp)
+	if errors.Is(err, os.ErrNotExist) {
+		return &TemplateSpec{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fp, err)
+	}
+
+	spec := &TemplateSpec{}
+
+	if err := yaml.Unmarshal(bts, spec); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", fp, err)
+	}
+
+	for _, p := range spec.Params {
+		if p.Type == "" {
+			p.Type = "string"
+		}
+
+		if p.Default != nil {
+			if err := checkParamType(p, p.Default); err != nil {
+				return nil, fmt.Errorf("invalid default in %s: %w", fp, err)
+			}
+		}
+	}
+
+	return spec, nil
+}
+
+// HasTemplateSpec returns true if the template dir declares its params
+func HasTemplateSpec(templateDir string) bool {
+	_, err := os.Stat(path.Join(templateDir, templateSpecFile))
+
+	return err == nil
+}
+
+// Resolve validates the params given by a service against the spec, and
+// fills in defaults
+func (s *TemplateSpec) Resolve(params map[string]any) (map[string]any, error) {
+	declared := map[string]*TemplateParam{}
+	for _, p := range s.Params {
+		declared[p.Name] = p
+	}
+
+	unknown := []string{}
+
+	for name := range params {
+		if declared[name] == nil {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+
+		return nil, fmt.Errorf("unknown params: %s", strings.Join(unknown, ", "))
+	}
+
+	out := map[string]any{}
+
+	for _, p := range s.Params {
+		v, ok := params[p.Name]
+		if !ok || v == nil {
+			if p.Required {
+				return nil, fmt.Errorf("param %s is required", p.Name)
+			}
+
+			out[p.Name] = p.Default
+
+			continue
+		}
+
+		if err := checkParamType(p, v); err != nil {
+			return nil, err
+		}
+
+		out[p.Name] = v
+	}
+
+	return out, nil
+}
+
+func checkParamType(p *TemplateParam, v any) error {
+	ok := false
+
+	switch p.Type {
+	case "string":
+		_, ok = v.(string)
+	case "int":
+		_, ok = v.(int)
+	case "number":
+		switch v.(type) {
+		case int, float64:
+			ok = true
+		}
+	case "bool":
+		_, ok = v.(bool)
+	case "list":
+		_, ok = v.([]any)
+	case "object":
+		_, ok = v.(map[string]any)
+	default:
+		return fmt.Errorf("param %s has unknown type %s", p.Name, p.Type)
+	}
+
+	if !ok {
+		return fmt.Errorf("param %s must be of type %s, got %v", p.Name, p.Type, v)
+	}
+
+	return nil
+}
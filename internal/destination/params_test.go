@@ -0,0 +1,182 @@
+package destination
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTemplateSpecResolve(t *testing.T) {
+	spec := &TemplateSpec{Params: []*TemplateParam{
+		{Name: "replicas", Type: "int", Default: 1},
+		{Name: "host", Type: "string", Required: true},
+		{Name: "ratio", Type: "number"},
+		{Name: "debug", Type: "bool", Default: false},
+		{Name: "args", Type: "list"},
+		{Name: "labels", Type: "object"},
+	}}
+
+	tests := []struct {
+		name    string
+		params  map[string]any
+		want    map[string]any
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			params: map[string]any{"host": "example.com"},
+			want: map[string]any{
+				"replicas": 1, "host": "example.com", "ratio": nil,
+				"debug": false, "args": nil, "labels": nil,
+			},
+		},
+		{
+			name: "all given",
+			params: map[string]any{
+				"replicas": 3, "host": "example.com", "ratio": 0.5, "debug": true,
+				"args": []any{"--v"}, "labels": map[string]any{"team": "core"},
+			},
+			want: map[string]any{
+				"replicas": 3, "host": "example.com", "ratio": 0.5, "debug": true,
+				"args": []any{"--v"}, "labels": map[string]any{"team": "core"},
+			},
+		},
+		{
+			name:   "int is a number",
+			params: map[string]any{"host": "example.com", "ratio": 2},
+			want: map[string]any{
+				"replicas": 1, "host": "example.com", "ratio": 2,
+				"debug": false, "args": nil, "labels": nil,
+			},
+		},
+		{
+			name:   "null uses the default",
+			params: map[string]any{"host": "example.com", "replicas": nil},
+			want: map[string]any{
+				"replicas": 1, "host": "example.com", "ratio": nil,
+				"debug": false, "args": nil, "labels": nil,
+			},
+		},
+		{
+			name:    "unknown params",
+			params:  map[string]any{"host": "example.com", "zone": "a", "cpu": 1},
+			wantErr: "unknown params: cpu, zone",
+		},
+		{
+			name:    "required",
+			params:  map[string]any{},
+			wantErr: "param host is required",
+		},
+		{
+			name:    "required null",
+			params:  map[string]any{"host": nil},
+			wantErr: "param host is required",
+		},
+		{
+			name:    "wrong type",
+			params:  map[string]any{"host": "example.com", "replicas": "3"},
+			wantErr: "param replicas must be of type int, got 3",
+		},
+		{
+			name:    "float isn't an int",
+			params:  map[string]any{"host": "example.com", "replicas": 1.5},
+			wantErr: "param replicas must be of type int, got 1.5",
+		},
+		{
+			name:    "wrong collection",
+			params:  map[string]any{"host": "example.com", "args": map[string]any{}},
+			wantErr: "param args must be of type list, got map[]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := spec.Resolve(tt.params)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("got error %v, want %s", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadTemplateSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    *TemplateSpec
+		wantErr bool
+	}{
+		{
+			name: "no spec",
+			want: &TemplateSpec{},
+		},
+		{
+			name: "type defaults to string",
+			spec: "params:\n- name: host\n  default: example.com\n",
+			want: &TemplateSpec{Params: []*TemplateParam{
+				{Name: "host", Type: "string", Default: "example.com"},
+			}},
+		},
+		{
+			name:    "bad default",
+			spec:    "params:\n- name: replicas\n  type: int\n  default: many\n",
+			wantErr: true,
+		},
+		{
+			name:    "unknown type",
+			spec:    "params:\n- name: replicas\n  type: integer\n  default: 1\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid yaml",
+			spec:    "params: [",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			if tt.spec != "" {
+				if err := os.WriteFile(filepath.Join(dir, templateSpecFile), []byte(tt.spec), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := LoadTemplateSpec(dir)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadTemplateSpec should fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("LoadTemplateSpec: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+
+			if HasTemplateSpec(dir) != (tt.spec != "") {
+				t.Errorf("HasTemplateSpec = %v", HasTemplateSpec(dir))
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package destination
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// secretsFile is the file in the deploy bundle holding its secrets
+const secretsFile = "secrets.yaml"
+
+// externalSecretRefresh is how often external secrets are read from the store
+const externalSecretRefresh = "1h"
+
+// SecretVariable is an environment variable set from a key of a secret
+type SecretVariable struct {
+	Name       string
+	SecretName string
+	Key        string
+}
+
+// SecretName is the name of a service's secret in a deploy. e.g. s-foo-v1-db
+func SecretName(slugNameVersion, name string) string {
+	return fmt.Sprintf("%s-%s", slugNameVersion, name)
+}
+
+// SealedSecret is a SealedSecret manifest of the sealed values, creating the
+// secret name
+func SealedSecret(args *DeployContext, name string, sealed map[string]string) any {
+	return map[string]any{
+		"apiVersion": "bitnami.com/v1alpha1",
+		"kind":       "SealedSecret",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": args.Namespace,
+		},
+		"spec": map[string]any{
+			"encryptedData": sealed,
+			"template": map[string]any{
+				"metadata": map[string]any{
+					"name":      name,
+					"namespace": args.Namespace,
+				},
+			},
+		},
+	}
+}
+
+// ExternalSecret is an ExternalSecret manifest creating the secret name from
+// the keys in the store. Keys can have a #property.
+func ExternalSecret(args *DeployContext, name, store, storeKind string, keys map[string]string) any {
+	secretKeys := make([]string, 0, len(keys))
+	for k := range keys {
+		secretKeys = append(secretKeys, k)
+	}
+
+	sort.Strings(secretKeys)
+
+	data := []any{}
+
+	for _, k := range secretKeys {
+		ref := map[string]any{"key": keys[k]}
+
+		if key, property, ok := strings.Cut(keys[k], "#"); ok {
+			ref["key"] = key
+			ref["property"] = property
+		}
+
+		data = append(data, map[string]any{"secretKey": k, "remoteRef": ref})
+	}
+
+	if storeKind == "" {
+		storeKind = "SecretStore"
+	}
+
+	return map[string]any{
+		"apiVersion": "external-secrets.io/v1beta1",
+		"kind":       "ExternalSecret",
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": args.Namespace,
+		},
+		"spec": map[string]any{
+			"refreshInterval": externalSecretRefresh,
+			"secretStoreRef": map[string]any{
+				"name": store,
+				"kind": storeKind,
+			},
+			"target": map[string]any{
+				"name":           name,
+				"creationPolicy": "Owner",
+			},
+			"data": data,
+		},
+	}
+}
+
+// AddSecrets renders the secret manifests into the bundle
+func AddSecrets(args *DeployContext, secrets []any, files []*RenderedFile) ([]*RenderedFile, error) {
+	if len(secrets) == 0 {
+		return files, nil
+	}
+
+	for _, f := range files {
+		if f.Name == secretsFile {
+			return nil, fmt.Errorf("the template can't have a %s, it holds the secrets", secretsFile)
+		}
+	}
+
+	data, err := encodeYAML(secrets...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode secrets: %w", err)
+	}
+
+	f := &RenderedFile{Name: secretsFile, Data: data, Generated: true}
+
+	if err := AttributeDeployBundle(args, []*RenderedFile{f}); err != nil {
+		return nil, err
+	}
+
+	return append(files, f), nil
+}
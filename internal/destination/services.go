@@ -2,6 +2,8 @@ package destination
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -66,20 +68,75 @@ type Release struct {
 // LoadServices produces services by parsing kubernetes manifests and
 // mapping over annotations according to the desired spec.
 func LoadServices(rootDir string) (Services, error) {
-	manifests := []*Manifest{}
-
 	filepaths, err := glob(rootDir)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, fp := range filepaths {
+	return loadServices(filepaths)
+}
+
+// LoadProjectServices is like LoadServices, but only parses the service
+// directories (rootDir/<service>) that belong to the project. The project of
+// a directory is read from the shipper/project annotation of its first
+// manifest, so other projects sharing the manifest path are skipped.
+func LoadProjectServices(rootDir, project string) (Services, error) {
+	entries, err := os.ReadDir(rootDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Services{}, nil
+		}
+
+		return nil, err
+	}
+
+	filepaths := []string{}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		files, err := glob(filepath.Join(rootDir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		p, err := projectOf(files)
+		if err != nil {
+			return nil, err
+		}
+
+		if p == project {
+			filepaths = append(filepaths, files...)
+		}
+	}
+
+	return loadServices(filepaths)
+}
+
+// projectOf returns the first shipper/project annotation found in the files
+func projectOf(files []string) (string, error) {
+	for _, fp := range files {
 		mfs, err := openAndParseManifest(fp)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse file %s: %w", fp, err)
+			return "", fmt.Errorf("failed to parse file %s: %w", fp, err)
 		}
 
-		manifests = append(manifests, mfs...)
+		for _, mf := range mfs {
+			if p := mf.Annotations["shipper/project"]; p != "" {
+				return p, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func loadServices(filepaths []string) (Services, error) {
+	manifests, err := parseFiles(filepaths)
+	if err != nil {
+		return nil, err
 	}
 
 	svcs := Services{}
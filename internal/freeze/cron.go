@@ -0,0 +1,129 @@
+package freeze
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a parsed five field cron schedule
+type Cron struct {
+	minute  field
+	hour    field
+	day     field
+	month   field
+	weekday field
+
+	// when both day and weekday are restricted, either may match
+	dayStar     bool
+	weekdayStar bool
+}
+
+// field is the set of values allowed for one part of the schedule
+type field map[int]bool
+
+// ParseCron parses a schedule of "minute hour day month weekday". Each field
+// supports *, values, ranges (1-5), lists (1,3) and steps (*/15, 1-10/2).
+func ParseCron(expr string) (*Cron, error) {
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("cron %q must have 5 fields", expr)
+	}
+
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 6}}
+	fields := [5]field{}
+
+	for i, p := range parts {
+		f, err := parseField(p, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron %q: %w", expr, err)
+		}
+
+		fields[i] = f
+	}
+
+	// sunday can be written as 7
+	if fields[4][7] {
+		fields[4][0] = true
+	}
+
+	return &Cron{
+		minute:      fields[0],
+		hour:        fields[1],
+		day:         fields[2],
+		month:       fields[3],
+		weekday:     fields[4],
+		dayStar:     parts[2] == "*",
+		weekdayStar: parts[4] == "*",
+	}, nil
+}
+
+func parseField(s string, min, max int) (field, error) {
+	f := field{}
+
+	for _, part := range strings.Split(s, ",") {
+		rng, step := part, 1
+
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("bad step in %q", part)
+			}
+
+			rng, step = part[:i], n
+		}
+
+		lo, hi := min, max
+
+		if rng != "*" {
+			var err error
+
+			a, b, isRange := strings.Cut(rng, "-")
+
+			lo, err = strconv.Atoi(a)
+			if err != nil {
+				return nil, fmt.Errorf("bad value %q", a)
+			}
+
+			hi = lo
+
+			if isRange {
+				hi, err = strconv.Atoi(b)
+				if err != nil {
+					return nil, fmt.Errorf("bad value %q", b)
+				}
+			}
+
+			// weekday allows 7 for sunday
+			if max == 6 {
+				max = 7
+			}
+
+			if lo < min || hi > max || lo > hi {
+				return nil, fmt.Errorf("%q out of range %d-%d", rng, min, max)
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			f[v] = true
+		}
+	}
+
+	return f, nil
+}
+
+// Matches returns true if the schedule fires in the minute of t
+func (c *Cron) Matches(t time.Time) bool {
+	if !c.minute[t.Minute()] || !c.hour[t.Hour()] || !c.month[int(t.Month())] {
+		return false
+	}
+
+	day, weekday := c.day[t.Day()], c.weekday[int(t.Weekday())]
+
+	if !c.dayStar && !c.weekdayStar {
+		return day || weekday
+	}
+
+	return day && weekday
+}
@@ -0,0 +1,80 @@
+package freeze
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"too few fields", "* * * *"},
+		{"too many fields", "* * * * * *"},
+		{"minute out of range", "60 * * * *"},
+		{"hour out of range", "* 24 * * *"},
+		{"day out of range", "* * 0 * *"},
+		{"month out of range", "* * * 13 *"},
+		{"weekday out of range", "* * * * 8"},
+		{"zero step", "*/0 * * * *"},
+		{"bad step", "*/x * * * *"},
+		{"bad value", "a * * * *"},
+		{"bad range end", "1-x * * * *"},
+		{"backwards range", "5-1 * * * *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseCron(tt.expr); err == nil {
+				t.Errorf("ParseCron(%q) should fail", tt.expr)
+			}
+		})
+	}
+}
+
+func TestCronMatches(t *testing.T) {
+	// 2024-01-01 is a monday
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2024, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		at   time.Time
+		want bool
+	}{
+		{"every minute", "* * * * *", at(3, 9, 13, 37), true},
+		{"step matches", "*/15 * * * *", at(1, 1, 10, 30), true},
+		{"step misses", "*/15 * * * *", at(1, 1, 10, 31), false},
+		{"ranged step matches", "0-10/5 * * * *", at(1, 1, 10, 10), true},
+		{"ranged step misses", "0-10/5 * * * *", at(1, 1, 10, 7), false},
+		{"ranged step past range", "0-10/5 * * * *", at(1, 1, 10, 15), false},
+		{"office hours", "0 9-17 * * 1-5", at(1, 1, 9, 0), true},
+		{"office hours weekend", "0 9-17 * * 1-5", at(1, 6, 9, 0), false},
+		{"office hours evening", "0 9-17 * * 1-5", at(1, 1, 18, 0), false},
+		{"sunday as 7", "0 0 * * 7", at(1, 7, 0, 0), true},
+		{"sunday as 0", "0 0 * * 0", at(1, 7, 0, 0), true},
+		{"list of days", "30 1 1,15 6 *", at(6, 15, 1, 30), true},
+		{"list of days wrong month", "30 1 1,15 6 *", at(7, 15, 1, 30), false},
+		{"day only", "0 0 13 * *", at(1, 13, 0, 0), true},
+		{"day only misses", "0 0 13 * *", at(1, 5, 0, 0), false},
+		{"day or weekday by weekday", "0 0 13 * 5", at(1, 5, 0, 0), true},
+		{"day or weekday by day", "0 0 13 * 5", at(1, 13, 0, 0), true},
+		{"day or weekday neither", "0 0 13 * 5", at(1, 14, 0, 0), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+			}
+
+			if got := c.Matches(tt.at); got != tt.want {
+				t.Errorf("%q matches %s = %v, want %v", tt.expr, tt.at, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+// Package freeze evaluates the freeze windows in project config
+package freeze
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cygnetdigital/shipper/internal/conf"
+)
+
+// Active returns true if the freeze window covers now
+func Active(f *conf.ProjectFreeze, now time.Time) (bool, error) {
+	loc := time.UTC
+
+	if f.Timezone != "" {
+		var err error
+
+		loc, err = time.LoadLocation(f.Timezone)
+		if err != nil {
+			return false, fmt.Errorf("invalid timezone for freeze %s: %w", f.Name, err)
+		}
+	}
+
+	now = now.In(loc)
+
+	if f.Cron != "" {
+		return cronActive(f, now)
+	}
+
+	if f.Start == "" && f.End == "" {
+		return false, fmt.Errorf("freeze %s needs a start and end, or a cron", f.Name)
+	}
+
+	if f.Start != "" {
+		start, _, err := parseTime(f.Start, loc)
+		if err != nil {
+			return false, fmt.Errorf("invalid start for freeze %s: %w", f.Name, err)
+		}
+
+		if now.Before(start) {
+			return false, nil
+		}
+	}
+
+	if f.End != "" {
+		end, dateOnly, err := parseTime(f.End, loc)
+		if err != nil {
+			return false, fmt.Errorf("invalid end for freeze %s: %w", f.Name, err)
+		}
+
+		if dateOnly {
+			end = end.AddDate(0, 0, 1)
+		}
+
+		if !now.Before(end) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Applies returns true if the freeze covers the service
+func Applies(f *conf.ProjectFreeze, service string) bool {
+	if len(f.Services) == 0 {
+		return true
+	}
+
+	for _, s := range f.Services {
+		if s == service {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cronActive looks back over the duration for a minute the schedule fired
+func cronActive(f *conf.ProjectFreeze, now time.Time) (bool, error) {
+	cron, err := ParseCron(f.Cron)
+	if err != nil {
+		return false, fmt.Errorf("invalid cron for freeze %s: %w", f.Name, err)
+	}
+
+	if f.Duration == "" {
+		return false, fmt.Errorf("freeze %s needs a duration with its cron", f.Name)
+	}
+
+	d, err := time.ParseDuration(f.Duration)
+	if err != nil {
+		return false, fmt.Errorf("invalid duration for freeze %s: %w", f.Name, err)
+	}
+
+	now = now.Truncate(time.Minute)
+
+	for t := now; now.Sub(t) < d; t = t.Add(-time.Minute) {
+		if cron.Matches(t) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// parseTime parses a date or RFC3339 time, reporting if it was only a date
+func parseTime(s string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+		return t, true, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return t, false, nil
+}
@@ -0,0 +1,225 @@
+// Package gitcache keeps bare mirrors of remote repositories on disk so they
+// can be fetched incrementally, rather than cloned from scratch for every
+// operation.
+package gitcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/go-git/go-git/v5/storage/transactional"
+)
+
+// Cache of repository mirrors
+type Cache struct {
+	dir string
+}
+
+// New cache in dir
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// DefaultDir is the cache directory used unless SHIPPER_CACHE_DIR is set. e.g.
+// ~/.cache/shipper/repos
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("SHIPPER_CACHE_DIR"); dir != "" {
+		return dir, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache dir: %w", err)
+	}
+
+	return filepath.Join(dir, "shipper", "repos"), nil
+}
+
+// Dir of the cache
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Clean removes every mirror from the cache, waiting for any being fetched
+// to finish first
+func (c *Cache) Clean(ctx context.Context) error {
+	entries, err := os.ReadDir(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read cache dir: %w", err)
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+
+		dir := filepath.Join(c.dir, e.Name())
+
+		unlock, err := lock(ctx, dir+".lock")
+		if err != nil {
+			return err
+		}
+
+		err = os.RemoveAll(dir)
+
+		unlock()
+
+		if err != nil {
+			return fmt.Errorf("failed to remove %s: %w", dir, err)
+		}
+	}
+
+	return nil
+}
+
+var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9\-_.]+`)
+
+// mirrorDir for a url is readable, with a hash to avoid collisions
+func (c *Cache) mirrorDir(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	name := unsafeChars.ReplaceAllString(url, "_")
+
+	if len(name) > 64 {
+		name = name[len(name)-64:]
+	}
+
+	return filepath.Join(c.dir, fmt.Sprintf("%s-%x", name, sum[:4]))
+}
+
+// Mirror fetches the latest state of the remote into its mirror, creating it
+// if this is the first use.
+func (c *Cache) Mirror(ctx context.Context, url string, auth transport.AuthMethod) (*Mirror, error) {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache dir: %w", err)
+	}
+
+	dir := c.mirrorDir(url)
+
+	// fetching only ever adds objects and moves refs, so worktrees reading
+	// from the mirror are safe without holding the lock
+	unlock, err := lock(ctx, dir+".lock")
+	if err != nil {
+		return nil, err
+	}
+
+	defer unlock()
+
+	repo, err := git.PlainOpen(dir)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		repo, err = initMirror(dir, url)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mirror: %w", err)
+	}
+
+	err = repo.FetchContext(ctx, &git.FetchOptions{RemoteName: "origin", Auth: auth, Force: true})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+
+	return &Mirror{dir: dir, repo: repo}, nil
+}
+
+func initMirror(dir, url string) (*git.Repository, error) {
+	repo, err := git.PlainInit(dir, true)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name: "origin",
+		URLs: []string{url},
+		Fetch: []config.RefSpec{
+			"+refs/heads/*:refs/heads/*",
+			"+refs/tags/*:refs/tags/*",
+		},
+	})
+	if err != nil {
+		//nolint:errcheck
+		os.RemoveAll(dir)
+
+		return nil, err
+	}
+
+	return repo, nil
+}
+
+// Mirror is a bare copy of a remote repository
+type Mirror struct {
+	dir  string
+	repo *git.Repository
+}
+
+// DefaultBranch of the remote, read from the remote HEAD
+func (m *Mirror) DefaultBranch(ctx context.Context, auth transport.AuthMethod) (string, error) {
+	remote, err := m.repo.Remote("origin")
+	if err != nil {
+		return "", err
+	}
+
+	refs, err := remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", fmt.Errorf("failed to list remote refs: %w", err)
+	}
+
+	var head *plumbing.Reference
+
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD {
+			head = ref
+		}
+	}
+
+	if head == nil {
+		return "", fmt.Errorf("remote has no HEAD")
+	}
+
+	if head.Type() == plumbing.SymbolicReference {
+		return head.Target().Short(), nil
+	}
+
+	for _, ref := range refs {
+		if ref.Name().IsBranch() && ref.Hash() == head.Hash() {
+			return ref.Name().Short(), nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to resolve remote HEAD")
+}
+
+// Repository opens a repository without a worktree which reads objects from
+// the mirror. Anything written (commits and refs) is kept in memory, leaving
+// the mirror untouched until the next fetch.
+func (m *Mirror) Repository() (*git.Repository, error) {
+	return git.Open(m.storage(), nil)
+}
+
+// Worktree opens a repository like Repository, checked out into dir
+func (m *Mirror) Worktree(dir string) (*git.Repository, error) {
+	return git.Open(m.storage(), osfs.New(dir))
+}
+
+func (m *Mirror) storage() storage.Storer {
+	base := filesystem.NewStorage(osfs.New(m.dir), cache.NewObjectLRUDefault())
+
+	return transactional.NewStorage(base, memory.NewStorage())
+}
@@ -0,0 +1,70 @@
+package gitcache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lock files without a pid are assumed stale after this, as the process
+// died between creating and writing them
+var unwrittenLockAge = time.Minute
+
+// lock takes an exclusive lock file, waiting for any other shipper process
+// holding it to finish. Locks held by processes which are no longer running
+// are taken over. The returned func releases the lock.
+func lock(ctx context.Context, path string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock dir: %w", err)
+	}
+
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+
+			//nolint:errcheck
+			return func() { os.Remove(path) }, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		if stale(path) {
+			//nolint:errcheck
+			os.Remove(path)
+
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("timed out waiting for lock %s: %w", path, ctx.Err())
+
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// stale returns true if the process which took the lock has exited
+func stale(path string) bool {
+	bts, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(bts)))
+	if err != nil {
+		stat, err := os.Stat(path)
+
+		return err == nil && time.Since(stat.ModTime()) > unwrittenLockAge
+	}
+
+	return !processRunning(pid)
+}
@@ -0,0 +1,22 @@
+//go:build !windows
+
+package gitcache
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// processRunning returns true if the process exists, by sending it the null
+// signal
+func processRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	err = p.Signal(syscall.Signal(0))
+
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,17 @@
+package gitcache
+
+import "os"
+
+// processRunning returns true if the process exists, which it must for it
+// to be opened
+func processRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	//nolint:errcheck
+	p.Release()
+
+	return true
+}
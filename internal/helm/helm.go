@@ -0,0 +1,186 @@
+// Package helm renders helm charts with the helm cli, caching pulled charts.
+package helm
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Client runs the helm cli
+type Client struct {
+	binary   string
+	cacheDir string
+	noCache  bool
+}
+
+// New client caching pulled charts in the dir, or the default dir if empty
+func New(cacheDir string) *Client {
+	return &Client{binary: "helm", cacheDir: cacheDir}
+}
+
+// NewUncached client which renders oci charts straight from the registry,
+// without caching them
+func NewUncached() *Client {
+	return &Client{binary: "helm", noCache: true}
+}
+
+// DefaultDir is the default chart cache dir
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache dir: %w", err)
+	}
+
+	return filepath.Join(dir, "shipper", "charts"), nil
+}
+
+// IsOCI returns true if the ref is an oci:// chart
+func IsOCI(ref string) bool {
+	return strings.HasPrefix(ref, "oci://")
+}
+
+// Pull the oci chart at the version, returning the dir it is unpacked in.
+// Charts are only pulled once, as versions are immutable.
+func (c *Client) Pull(ctx context.Context, ref, version string) (string, error) {
+	if version == "" {
+		return "", fmt.Errorf("a chart version is required for %s", ref)
+	}
+
+	cacheDir := c.cacheDir
+	if cacheDir == "" {
+		var err error
+		if cacheDir, err = DefaultDir(); err != nil {
+			return "", err
+		}
+	}
+
+	sum := sha256.Sum256([]byte(ref + "@" + version))
+	dir := filepath.Join(cacheDir, hex.EncodeToString(sum[:8]))
+
+	if chartDir, err := findChart(dir); err == nil {
+		return chartDir, nil
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create chart cache: %w", err)
+	}
+
+	temp, err := os.MkdirTemp(cacheDir, "pull-")
+	if err != nil {
+		return "", err
+	}
+
+	//nolint:errcheck
+	defer os.RemoveAll(temp)
+
+	if _, err := c.run(ctx, "pull", ref, "--version", version, "--untar", "--untardir", temp); err != nil {
+		return "", fmt.Errorf("failed to pull %s: %w", ref, err)
+	}
+
+	if err := os.Rename(temp, dir); err != nil && !errors.Is(err, os.ErrExist) {
+		return "", fmt.Errorf("failed to cache %s: %w", ref, err)
+	}
+
+	return findChart(dir)
+}
+
+// findChart returns the chart dir unpacked in dir
+func findChart(dir string) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*", "Chart.yaml"))
+	if err != nil {
+		return "", err
+	}
+
+	if len(matches) != 1 {
+		return "", fmt.Errorf("no chart found in %s", dir)
+	}
+
+	return filepath.Dir(matches[0]), nil
+}
+
+// Template renders the chart as the release. The chart is a dir, or an oci
+// chart at the version, which is pulled into the cache first unless caching
+// is turned off.
+func (c *Client) Template(ctx context.Context, release, chart, version, namespace string, values map[string]any) ([]byte, error) {
+	args := []string{"template", release, chart}
+
+	if IsOCI(chart) {
+		if c.noCache {
+			if version == "" {
+				return nil, fmt.Errorf("a chart version is required for %s", chart)
+			}
+
+			args = append(args, "--version", version)
+		} else {
+			dir, err := c.Pull(ctx, chart, version)
+			if err != nil {
+				return nil, err
+			}
+
+			args[2] = dir
+		}
+	}
+
+	bts, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode values: %w", err)
+	}
+
+	f, err := os.CreateTemp("", "shipper-values-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:errcheck
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(bts); err != nil {
+		f.Close()
+
+		return nil, err
+	}
+
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	args = append(args, "--values", f.Name(), "--skip-tests")
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+
+	out, err := c.run(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to template %s: %w", chart, err)
+	}
+
+	return out, nil
+}
+
+func (c *Client) run(ctx context.Context, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(c.binary); err != nil {
+		return nil, fmt.Errorf("%s is required to render charts: %w", c.binary, err)
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+	cmd := exec.CommandContext(ctx, c.binary, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,291 @@
+// Package policy evaluates CEL rules against rendered manifests.
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/ext"
+	"gopkg.in/yaml.v3"
+)
+
+// Dir is where policies are kept in the gitops repo
+const Dir = "policies"
+
+// Severity of a rule being broken
+type Severity string
+
+// Severities of rules
+const (
+	SeverityWarn Severity = "warn"
+	SeverityDeny Severity = "deny"
+)
+
+// File of rules in the policies dir
+type File struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule is a CEL expression which must be true for every manifest it applies
+// to. It is evaluated with object, the manifest, and shipper, a map of the
+// project, service and namespace being deployed to.
+type Rule struct {
+	Name       string   `yaml:"name"`
+	Severity   Severity `yaml:"severity"`
+	Message    string   `yaml:"message"`
+	Expression string   `yaml:"expression"`
+
+	// Kinds the rule applies to. Defaults to all kinds.
+	Kinds []string `yaml:"kinds"`
+
+	program cel.Program
+}
+
+// Input is what rules are evaluated with
+type Input struct {
+	File      string
+	Project   string
+	Service   string
+	Namespace string
+}
+
+// Result of a manifest breaking a rule
+type Result struct {
+	Rule     string
+	Severity Severity
+	File     string
+	Object   string
+	Message  string
+}
+
+func (r *Result) String() string {
+	return fmt.Sprintf("%s %s: %s: %s: %s", r.Severity, r.Rule, r.File, r.Object, r.Message)
+}
+
+// Set of compiled rules
+type Set struct {
+	Rules []*Rule
+}
+
+// Load the rules from the yaml files in dir. A missing dir has no rules.
+func Load(dir string) (*Set, error) {
+	if dir == "" {
+		return &Set{}, nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(files)
+
+	env, err := newEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	set := &Set{}
+
+	for _, fp := range files {
+		bts, err := os.ReadFile(fp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", fp, err)
+		}
+
+		f := &File{}
+
+		if err := yaml.Unmarshal(bts, f); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", fp, err)
+		}
+
+		for _, r := range f.Rules {
+			if err := r.compile(env); err != nil {
+				return nil, fmt.Errorf("invalid rule %s in %s: %w", r.Name, filepath.Base(fp), err)
+			}
+		}
+
+		set.Rules = append(set.Rules, f.Rules...)
+	}
+
+	return set, nil
+}
+
+func newEnv() (*cel.Env, error) {
+	env, err := cel.NewEnv(
+		cel.Variable("object", cel.MapType(cel.StringType, cel.DynType)),
+		cel.Variable("shipper", cel.MapType(cel.StringType, cel.StringType)),
+		ext.Strings(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup CEL: %w", err)
+	}
+
+	return env, nil
+}
+
+func (r *Rule) compile(env *cel.Env) error {
+	if r.Name == "" {
+		return errors.New("rules need a name")
+	}
+
+	switch r.Severity {
+	case SeverityWarn, SeverityDeny:
+	case "":
+		r.Severity = SeverityDeny
+	default:
+		return fmt.Errorf("unknown severity '%s'", r.Severity)
+	}
+
+	ast, iss := env.Compile(r.Expression)
+	if iss.Err() != nil {
+		return iss.Err()
+	}
+
+	if !cel.BoolType.IsAssignableType(ast.OutputType()) {
+		return fmt.Errorf("expression must be a bool, not %s", ast.OutputType())
+	}
+
+	prg, err := env.Program(ast)
+	if err != nil {
+		return err
+	}
+
+	r.program = prg
+
+	return nil
+}
+
+func (r *Rule) applies(kind string) bool {
+	if len(r.Kinds) == 0 {
+		return true
+	}
+
+	for _, k := range r.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Evaluate the rules against the manifest, given as json, returning the
+// rules it breaks. Rules which fail to evaluate are broken.
+func (s *Set) Evaluate(in *Input, manifest []byte) ([]*Result, error) {
+	obj := map[string]any{}
+
+	if err := json.Unmarshal(manifest, &obj); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+
+	obj = normalize(obj).(map[string]any)
+
+	kind, _ := obj["kind"].(string)
+
+	name := ""
+	if meta, ok := obj["metadata"].(map[string]any); ok {
+		name, _ = meta["name"].(string)
+	}
+
+	vars := map[string]any{
+		"object": obj,
+		"shipper": map[string]string{
+			"project":   in.Project,
+			"service":   in.Service,
+			"namespace": in.Namespace,
+		},
+	}
+
+	results := []*Result{}
+
+	for _, r := range s.Rules {
+		if !r.applies(kind) {
+			continue
+		}
+
+		msg := r.Message
+		if msg == "" {
+			msg = fmt.Sprintf("breaks %s", r.Expression)
+		}
+
+		out, _, err := r.program.Eval(vars)
+		if err != nil {
+			msg = fmt.Sprintf("failed to evaluate: %s", err)
+		} else if ok, isBool := out.Value().(bool); isBool && ok {
+			continue
+		}
+
+		results = append(results, &Result{
+			Rule:     r.Name,
+			Severity: r.Severity,
+			File:     in.File,
+			Object:   fmt.Sprintf("%s %s", kind, name),
+			Message:  msg,
+		})
+	}
+
+	return results, nil
+}
+
+// normalize whole numbers from json to ints, so rules can compare them with
+// int literals
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, val := range v {
+			v[k] = normalize(val)
+		}
+
+		return v
+	case []any:
+		for i, val := range v {
+			v[i] = normalize(val)
+		}
+
+		return v
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return int64(v)
+		}
+
+		return v
+	default:
+		return v
+	}
+}
+
+// Denied returns true if any of the results deny
+func Denied(results []*Result) bool {
+	for _, r := range results {
+		if r.Severity == SeverityDeny {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DeniedError is returned when manifests break deny rules
+type DeniedError struct {
+	Results []*Result
+}
+
+func (e *DeniedError) Error() string {
+	lines := []string{}
+
+	for _, r := range e.Results {
+		if r.Severity == SeverityDeny {
+			lines = append(lines, "  "+r.String())
+		}
+	}
+
+	return fmt.Sprintf("denied by policy:\n%s", strings.Join(lines, "\n"))
+}
@@ -0,0 +1,280 @@
+// Package registry provides a minimal client for the OCI distribution API,
+// enough to check for and resolve image manifests.
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned when a manifest does not exist in the registry
+var ErrNotFound = errors.New("manifest not found")
+
+// manifest media types we are happy to accept
+var acceptedManifests = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Client talks to container registries
+type Client struct {
+	http *http.Client
+
+	// basic auth credentials keyed by registry host
+	auths map[string]string
+}
+
+// NewClient sets up a registry client using credentials from the docker
+// config file, if there is one.
+func NewClient() *Client {
+	return &Client{
+		http:  http.DefaultClient,
+		auths: loadDockerAuths(),
+	}
+}
+
+// Descriptor of a manifest in the registry
+type Descriptor struct {
+	MediaType string
+	Digest    string
+}
+
+// Manifest looks up the manifest for image (e.g. gcr.io/foo/service.foo) at
+// the given tag or digest. ErrNotFound is returned if it does not exist.
+func (c *Client) Manifest(ctx context.Context, image, reference string) (*Descriptor, error) {
+	host, name := splitImage(image)
+
+	u := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme(host), host, name, reference)
+
+	resp, err := c.do(ctx, http.MethodHead, u, host, name)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return &Descriptor{
+			MediaType: resp.Header.Get("Content-Type"),
+			Digest:    resp.Header.Get("Docker-Content-Digest"),
+		}, nil
+
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+
+	default:
+		return nil, fmt.Errorf("unexpected status from registry for %s:%s: %s", image, reference, resp.Status)
+	}
+}
+
+// do performs the request, following a registry auth challenge if one is
+// returned.
+func (c *Client) do(ctx context.Context, method, u, host, name string) (*http.Response, error) {
+	resp, err := c.request(ctx, method, u, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+
+	resp.Body.Close()
+
+	authz, err := c.authorize(ctx, resp.Header.Get("WWW-Authenticate"), host, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate with %s: %w", host, err)
+	}
+
+	return c.request(ctx, method, u, authz)
+}
+
+func (c *Client) request(ctx context.Context, method, u, authz string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", strings.Join(acceptedManifests, ","))
+
+	if authz != "" {
+		req.Header.Set("Authorization", authz)
+	}
+
+	return c.http.Do(req)
+}
+
+// authorize answers a WWW-Authenticate challenge with an Authorization header
+func (c *Client) authorize(ctx context.Context, challenge, host, name string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	basic := c.auths[host]
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if basic == "" {
+			return "", fmt.Errorf("no credentials found for %s", host)
+		}
+
+		return "Basic " + basic, nil
+
+	case "bearer":
+		realm, err := url.Parse(params["realm"])
+		if err != nil || params["realm"] == "" {
+			return "", fmt.Errorf("invalid bearer realm '%s'", params["realm"])
+		}
+
+		q := realm.Query()
+		if params["service"] != "" {
+			q.Set("service", params["service"])
+		}
+
+		q.Set("scope", fmt.Sprintf("repository:%s:pull", name))
+		realm.RawQuery = q.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+		if err != nil {
+			return "", err
+		}
+
+		if basic != "" {
+			req.Header.Set("Authorization", "Basic "+basic)
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return "", err
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("token request failed: %s", resp.Status)
+		}
+
+		var tok struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+
+		if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+			return "", fmt.Errorf("failed to decode token: %w", err)
+		}
+
+		if tok.Token == "" {
+			tok.Token = tok.AccessToken
+		}
+
+		return "Bearer " + tok.Token, nil
+
+	default:
+		return "", fmt.Errorf("unsupported auth challenge '%s'", challenge)
+	}
+}
+
+// parseChallenge parses a header like: Bearer realm="https://x",service="y"
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(challenge, " ")
+	params := map[string]string{}
+
+	for _, part := range strings.Split(rest, ",") {
+		k, v, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+
+		params[strings.ToLower(k)] = strings.Trim(v, `"`)
+	}
+
+	return scheme, params
+}
+
+// splitImage splits an image into registry host and repository name
+func splitImage(image string) (string, string) {
+	host, name, found := strings.Cut(image, "/")
+	if !found || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		host, name = "docker.io", image
+
+		if !strings.Contains(name, "/") {
+			name = "library/" + name
+		}
+	}
+
+	if host == "docker.io" {
+		host = "registry-1.docker.io"
+	}
+
+	return host, name
+}
+
+// scheme to use for a registry host. Local registries are plain http.
+func scheme(host string) string {
+	hostname := strings.Split(host, ":")[0]
+	if hostname == "localhost" || hostname == "127.0.0.1" {
+		return "http"
+	}
+
+	return "https"
+}
+
+// loadDockerAuths reads basic auth credentials from the docker config file
+func loadDockerAuths() map[string]string {
+	out := map[string]string{}
+
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return out
+		}
+
+		dir = filepath.Join(home, ".docker")
+	}
+
+	bts, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return out
+	}
+
+	var cfg struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}
+
+	if err := json.Unmarshal(bts, &cfg); err != nil {
+		return out
+	}
+
+	for k, v := range cfg.Auths {
+		host := strings.TrimPrefix(strings.TrimPrefix(k, "https://"), "http://")
+		host = strings.Split(host, "/")[0]
+
+		if host == "index.docker.io" {
+			host = "registry-1.docker.io"
+		}
+
+		auth := v.Auth
+		if auth == "" && v.Username != "" {
+			auth = base64.StdEncoding.EncodeToString([]byte(v.Username + ":" + v.Password))
+		}
+
+		if auth != "" {
+			out[host] = auth
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,335 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDigest = "sha256:0000000000000000000000000000000000000000000000000000000000000000"
+
+// testRegistry serves the team/api:v1 manifest behind an optional auth
+// challenge, with a token endpoint for bearer challenges
+type testRegistry struct {
+	// challenge returned to requests without the authorization header
+	challenge     string
+	authorization string
+
+	// token endpoint response, and the basic auth it requires if set
+	token      string
+	tokenBasic string
+	tokenCode  int
+
+	code int
+}
+
+func (r *testRegistry) server(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	var srv *httptest.Server
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+		q := req.URL.Query()
+
+		if got := q.Get("scope"); got != "repository:team/api:pull" {
+			t.Errorf("token scope is %q", got)
+		}
+
+		if got := q.Get("service"); got != "test-registry" {
+			t.Errorf("token service is %q", got)
+		}
+
+		if r.tokenBasic != "" && req.Header.Get("Authorization") != "Basic "+r.tokenBasic {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		if r.tokenCode != 0 {
+			w.WriteHeader(r.tokenCode)
+
+			return
+		}
+
+		fmt.Fprint(w, r.token)
+	})
+
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodHead {
+			t.Errorf("manifest requested with %s", req.Method)
+		}
+
+		if got := req.Header.Get("Accept"); got != strings.Join(acceptedManifests, ",") {
+			t.Errorf("manifest requested accepting %q", got)
+		}
+
+		if r.challenge != "" && req.Header.Get("Authorization") != r.authorization {
+			w.Header().Set("WWW-Authenticate", strings.ReplaceAll(r.challenge, "$URL", srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		if r.code != 0 {
+			w.WriteHeader(r.code)
+
+			return
+		}
+
+		if req.URL.Path != "/v2/team/api/manifests/v1" {
+			w.WriteHeader(http.StatusNotFound)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		w.Header().Set("Docker-Content-Digest", testDigest)
+	})
+
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestManifest(t *testing.T) {
+	found := &Descriptor{MediaType: "application/vnd.oci.image.manifest.v1+json", Digest: testDigest}
+	bearer := `Bearer realm="$URL/token",service="test-registry"`
+
+	tests := []struct {
+		name     string
+		registry *testRegistry
+		auth     string
+		tag      string
+		want     *Descriptor
+		wantErr  error
+		errMsg   string
+	}{
+		{
+			name:     "anonymous",
+			registry: &testRegistry{},
+			want:     found,
+		},
+		{
+			name:     "not found",
+			registry: &testRegistry{},
+			tag:      "v2",
+			wantErr:  ErrNotFound,
+		},
+		{
+			name:     "unexpected status",
+			registry: &testRegistry{code: http.StatusInternalServerError},
+			errMsg:   "unexpected status from registry",
+		},
+		{
+			name:     "basic",
+			registry: &testRegistry{challenge: `Basic realm="test"`, authorization: "Basic dTpw"},
+			auth:     "dTpw",
+			want:     found,
+		},
+		{
+			name:     "basic without credentials",
+			registry: &testRegistry{challenge: `Basic realm="test"`, authorization: "Basic dTpw"},
+			errMsg:   "no credentials found",
+		},
+		{
+			name:     "bearer token",
+			registry: &testRegistry{challenge: bearer, authorization: "Bearer t1", token: `{"token":"t1"}`},
+			want:     found,
+		},
+		{
+			name:     "bearer access token",
+			registry: &testRegistry{challenge: bearer, authorization: "Bearer t1", token: `{"access_token":"t1"}`},
+			want:     found,
+		},
+		{
+			name:     "bearer token with credentials",
+			registry: &testRegistry{challenge: bearer, authorization: "Bearer t1", token: `{"token":"t1"}`, tokenBasic: "dTpw"},
+			auth:     "dTpw",
+			want:     found,
+		},
+		{
+			name:     "bearer token denied",
+			registry: &testRegistry{challenge: bearer, authorization: "Bearer t1", tokenCode: http.StatusForbidden},
+			errMsg:   "token request failed",
+		},
+		{
+			name:     "bad token",
+			registry: &testRegistry{challenge: bearer, authorization: "Bearer t1", token: "nope"},
+			errMsg:   "failed to decode token",
+		},
+		{
+			name:     "bearer without realm",
+			registry: &testRegistry{challenge: `Bearer service="test-registry"`, authorization: "Bearer t1"},
+			errMsg:   "invalid bearer realm",
+		},
+		{
+			name:     "unsupported challenge",
+			registry: &testRegistry{challenge: `Digest realm="test"`, authorization: "Digest x"},
+			errMsg:   "unsupported auth challenge",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := tt.registry.server(t)
+			host := strings.TrimPrefix(srv.URL, "http://")
+
+			c := &Client{http: srv.Client(), auths: map[string]string{}}
+			if tt.auth != "" {
+				c.auths[host] = tt.auth
+			}
+
+			tag := tt.tag
+			if tag == "" {
+				tag = "v1"
+			}
+
+			got, err := c.Manifest(context.Background(), host+"/team/api", tag)
+
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got error %v, want %v", err, tt.wantErr)
+				}
+			case tt.errMsg != "":
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatalf("got error %v, want %s", err, tt.errMsg)
+				}
+			case err != nil:
+				t.Fatalf("Manifest: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	tests := []struct {
+		challenge  string
+		wantScheme string
+		wantParams map[string]string
+	}{
+		{
+			challenge:  `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`,
+			wantScheme: "Bearer",
+			wantParams: map[string]string{"realm": "https://auth.docker.io/token", "service": "registry.docker.io"},
+		},
+		{
+			challenge:  `Basic Realm="registry", Charset="UTF-8"`,
+			wantScheme: "Basic",
+			wantParams: map[string]string{"realm": "registry", "charset": "UTF-8"},
+		},
+		{
+			challenge:  "Basic",
+			wantScheme: "Basic",
+			wantParams: map[string]string{},
+		},
+		{
+			challenge:  "",
+			wantScheme: "",
+			wantParams: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.challenge, func(t *testing.T) {
+			scheme, params := parseChallenge(tt.challenge)
+
+			if scheme != tt.wantScheme {
+				t.Errorf("scheme %q, want %q", scheme, tt.wantScheme)
+			}
+
+			if !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("params %v, want %v", params, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestSplitImage(t *testing.T) {
+	tests := []struct {
+		image    string
+		wantHost string
+		wantName string
+	}{
+		{"nginx", "registry-1.docker.io", "library/nginx"},
+		{"team/api", "registry-1.docker.io", "team/api"},
+		{"docker.io/team/api", "registry-1.docker.io", "team/api"},
+		{"gcr.io/project/api", "gcr.io", "project/api"},
+		{"localhost/api", "localhost", "api"},
+		{"localhost:5000/team/api", "localhost:5000", "team/api"},
+		{"127.0.0.1:5000/api", "127.0.0.1:5000", "api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			host, name := splitImage(tt.image)
+
+			if host != tt.wantHost || name != tt.wantName {
+				t.Errorf("got %s %s, want %s %s", host, name, tt.wantHost, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestLoadDockerAuths(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   map[string]string
+	}{
+		{
+			name: "auths",
+			config: `{"auths": {
+				"https://index.docker.io/v1/": {"auth": "ZDpw"},
+				"gcr.io": {"username": "u", "password": "p"},
+				"http://localhost:5000/v2/": {"auth": "bDpw"},
+				"quay.io": {}
+			}}`,
+			want: map[string]string{
+				"registry-1.docker.io": "ZDpw",
+				"gcr.io":               "dTpw",
+				"localhost:5000":       "bDpw",
+			},
+		},
+		{
+			name: "no config",
+			want: map[string]string{},
+		},
+		{
+			name:   "invalid config",
+			config: "{",
+			want:   map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("DOCKER_CONFIG", dir)
+
+			if tt.config != "" {
+				if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(tt.config), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := loadDockerAuths(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
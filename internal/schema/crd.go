@@ -0,0 +1,121 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// crd is the part of a CustomResourceDefinition holding its schemas
+type crd struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Spec       struct {
+		Group string `yaml:"group"`
+		Names struct {
+			Kind string `yaml:"kind"`
+		} `yaml:"names"`
+		Versions []struct {
+			Name   string `yaml:"name"`
+			Schema struct {
+				OpenAPIV3Schema map[string]any `yaml:"openAPIV3Schema"`
+			} `yaml:"schema"`
+		} `yaml:"versions"`
+	} `yaml:"spec"`
+}
+
+// AddCRDs adds the schemas of the apiextensions.k8s.io/v1 CRDs in the yaml
+// files under dir. Other objects in the files are ignored.
+func (v *Validator) AddCRDs(dir string) error {
+	return filepath.WalkDir(dir, func(fp string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || (filepath.Ext(fp) != ".yaml" && filepath.Ext(fp) != ".yml") {
+			return nil
+		}
+
+		bts, err := os.ReadFile(fp)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", fp, err)
+		}
+
+		if err := v.addCRDFile(bts); err != nil {
+			return fmt.Errorf("failed to load CRDs from %s: %w", fp, err)
+		}
+
+		return nil
+	})
+}
+
+func (v *Validator) addCRDFile(bts []byte) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(bts))
+
+	for {
+		c := &crd{}
+
+		if err := decoder.Decode(c); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		if c.APIVersion != "apiextensions.k8s.io/v1" || c.Kind != "CustomResourceDefinition" {
+			continue
+		}
+
+		for _, ver := range c.Spec.Versions {
+			s, err := toSchema(ver.Schema.OpenAPIV3Schema)
+			if err != nil {
+				return fmt.Errorf("invalid schema for %s %s: %w", ver.Name, c.Spec.Names.Kind, err)
+			}
+
+			if s == nil {
+				s = &Schema{PreserveUnknownFields: true}
+			}
+
+			gvk := GroupVersionKind{Group: c.Spec.Group, Version: ver.Name, Kind: c.Spec.Names.Kind}
+
+			// CRD schemas leave out the standard fields
+			if s.Properties == nil {
+				s.Properties = map[string]*Schema{}
+			}
+
+			s.Properties["apiVersion"] = &Schema{Type: "string"}
+			s.Properties["kind"] = &Schema{Type: "string"}
+			s.Properties["metadata"] = &Schema{Ref: refPrefix + "io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"}
+
+			v.kinds[gvk] = s
+		}
+	}
+}
+
+// toSchema converts the decoded yaml schema by round tripping it through json
+func toSchema(raw map[string]any) (*Schema, error) {
+	if raw == nil {
+		return nil, nil
+	}
+
+	bts, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Schema{}
+
+	if err := json.Unmarshal(bts, s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,110 @@
+//go:build ignore
+
+// gen trims the kubernetes openapi spec down to the definitions used for
+// validation, and writes it gzipped to the schemas dir.
+//
+//	go run gen.go v1.24.2
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+const specURL = "https://raw.githubusercontent.com/kubernetes/kubernetes/%s/api/openapi-spec/swagger.json"
+
+// keep are the schema keys used by the validator
+var keep = map[string]bool{
+	"$ref":                                 true,
+	"type":                                 true,
+	"format":                               true,
+	"properties":                           true,
+	"additionalProperties":                 true,
+	"items":                                true,
+	"required":                             true,
+	"enum":                                 true,
+	"x-kubernetes-group-version-kind":      true,
+	"x-kubernetes-int-or-string":           true,
+	"x-kubernetes-preserve-unknown-fields": true,
+}
+
+func main() {
+	if len(os.Args) != 2 {
+		log.Fatal("usage: go run gen.go <kubernetes version>")
+	}
+
+	version := os.Args[1]
+
+	resp, err := http.Get(fmt.Sprintf(specURL, version))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		log.Fatalf("failed to get spec: %s", resp.Status)
+	}
+
+	spec := struct {
+		Definitions map[string]map[string]any `json:"definitions"`
+	}{}
+
+	if err := json.NewDecoder(resp.Body).Decode(&spec); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, def := range spec.Definitions {
+		trim(def)
+	}
+
+	if err := write(filepath.Join("schemas", "kubernetes.json.gz"), spec.Definitions); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func trim(schema map[string]any) {
+	for k, v := range schema {
+		if !keep[k] {
+			delete(schema, k)
+
+			continue
+		}
+
+		switch k {
+		case "properties":
+			for _, p := range v.(map[string]any) {
+				trim(p.(map[string]any))
+			}
+		case "items", "additionalProperties":
+			if m, ok := v.(map[string]any); ok {
+				trim(m)
+			}
+		}
+	}
+}
+
+func write(fp string, defs any) error {
+	f, err := os.Create(fp)
+	if err != nil {
+		return err
+	}
+
+	zw, _ := gzip.NewWriterLevel(f, gzip.BestCompression)
+
+	if err := json.NewEncoder(io.Writer(zw)).Encode(defs); err != nil {
+		return err
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
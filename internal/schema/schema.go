@@ -0,0 +1,101 @@
+// Package schema validates rendered manifests against the kubernetes openapi
+// schemas, and the schemas of custom resources.
+package schema
+
+import (
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// KubernetesVersion the bundled schemas are from
+const KubernetesVersion = "v1.24.2"
+
+//go:generate go run gen.go v1.24.2
+//go:embed schemas/kubernetes.json.gz
+var kubernetesSchemas []byte
+
+const refPrefix = "#/definitions/"
+
+// Schema is the subset of an openapi schema used for validation
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Format               string             `json:"format"`
+	Properties           map[string]*Schema `json:"properties"`
+	AdditionalProperties *Additional        `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Required             []string           `json:"required"`
+	Enum                 []any              `json:"enum"`
+
+	GroupVersionKind      []GroupVersionKind `json:"x-kubernetes-group-version-kind"`
+	IntOrString           bool               `json:"x-kubernetes-int-or-string"`
+	PreserveUnknownFields bool               `json:"x-kubernetes-preserve-unknown-fields"`
+}
+
+// Additional is either a schema for additional properties, or whether they
+// are allowed at all
+type Additional struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+// UnmarshalJSON ...
+func (a *Additional) UnmarshalJSON(bts []byte) error {
+	if err := json.Unmarshal(bts, &a.Allowed); err == nil {
+		return nil
+	}
+
+	a.Allowed = true
+
+	return json.Unmarshal(bts, &a.Schema)
+}
+
+// GroupVersionKind identifies the schema of an object
+type GroupVersionKind struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// APIVersion of the kind, e.g. apps/v1
+func (g GroupVersionKind) APIVersion() string {
+	if g.Group == "" {
+		return g.Version
+	}
+
+	return g.Group + "/" + g.Version
+}
+
+func (g GroupVersionKind) String() string {
+	return fmt.Sprintf("%s %s", g.APIVersion(), g.Kind)
+}
+
+// parseAPIVersion into a group version kind
+func parseAPIVersion(apiVersion, kind string) GroupVersionKind {
+	group, version, found := strings.Cut(apiVersion, "/")
+	if !found {
+		return GroupVersionKind{Version: apiVersion, Kind: kind}
+	}
+
+	return GroupVersionKind{Group: group, Version: version, Kind: kind}
+}
+
+// loadKubernetes loads the bundled kubernetes definitions
+func loadKubernetes() (map[string]*Schema, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(kubernetesSchemas))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubernetes schemas: %w", err)
+	}
+
+	defs := map[string]*Schema{}
+
+	if err := json.NewDecoder(zr).Decode(&defs); err != nil {
+		return nil, fmt.Errorf("failed to decode kubernetes schemas: %w", err)
+	}
+
+	return defs, nil
+}
@@ -0,0 +1,324 @@
+package schema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Validator validates manifests against the kubernetes schemas and any CRDs
+// added to it
+type Validator struct {
+	defs  map[string]*Schema
+	kinds map[GroupVersionKind]*Schema
+}
+
+// New validator with the bundled kubernetes schemas
+func New() (*Validator, error) {
+	defs, err := loadKubernetes()
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Validator{defs: defs, kinds: map[GroupVersionKind]*Schema{}}
+
+	for _, def := range defs {
+		for _, gvk := range def.GroupVersionKind {
+			v.kinds[gvk] = def
+		}
+	}
+
+	return v, nil
+}
+
+// Problem with a field of an object in a manifest file
+type Problem struct {
+	File    string
+	Object  string
+	Field   string
+	Message string
+}
+
+func (p Problem) String() string {
+	out := p.File
+
+	if p.Object != "" {
+		out += ": " + p.Object
+	}
+
+	if p.Field != "" {
+		out += ": " + p.Field
+	}
+
+	return out + ": " + p.Message
+}
+
+// Error lists the problems found validating manifests
+type Error struct {
+	Problems []Problem
+}
+
+func (e *Error) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = "  " + p.String()
+	}
+
+	return fmt.Sprintf("invalid manifests:\n%s", strings.Join(lines, "\n"))
+}
+
+// ValidateFile validates every object in the yaml file
+func (v *Validator) ValidateFile(name string, data []byte) []Problem {
+	problems := []Problem{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	for i := 0; ; i++ {
+		var doc any
+
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return append(problems, Problem{File: name, Message: err.Error()})
+		}
+
+		if doc == nil {
+			continue
+		}
+
+		problems = append(problems, v.validateObject(name, i, doc)...)
+	}
+
+	return problems
+}
+
+func (v *Validator) validateObject(file string, index int, doc any) []Problem {
+	obj, ok := doc.(map[string]any)
+	if !ok {
+		return []Problem{{File: file, Message: fmt.Sprintf("document %d is not an object", index)}}
+	}
+
+	apiVersion, _ := obj["apiVersion"].(string)
+	kind, _ := obj["kind"].(string)
+
+	name := fmt.Sprintf("document %d", index)
+	if meta, ok := obj["metadata"].(map[string]any); ok {
+		if n, ok := meta["name"].(string); ok && n != "" {
+			name = n
+		}
+	}
+
+	object := fmt.Sprintf("%s %s", kind, name)
+
+	if apiVersion == "" || kind == "" {
+		return []Problem{{File: file, Object: object, Message: "apiVersion and kind are required"}}
+	}
+
+	gvk := parseAPIVersion(apiVersion, kind)
+
+	s, ok := v.kinds[gvk]
+	if !ok {
+		return []Problem{{File: file, Object: object, Message: fmt.Sprintf("no schema for %s, add its CRD to the gitops repo", gvk)}}
+	}
+
+	c := &check{v: v, file: file, target: object}
+	c.value("", obj, s)
+
+	return c.problems
+}
+
+// check collects the problems found validating an object
+type check struct {
+	v        *Validator
+	file     string
+	target   string
+	problems []Problem
+}
+
+func (c *check) fail(field, format string, args ...any) {
+	c.problems = append(c.problems, Problem{
+		File:    c.file,
+		Object:  c.target,
+		Field:   field,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *check) resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, refPrefix)
+
+		// quantities are strings in the schema, but numbers are accepted
+		if name == "io.k8s.apimachinery.pkg.api.resource.Quantity" {
+			return &Schema{Type: "string", Format: "quantity"}
+		}
+
+		s = c.v.defs[name]
+	}
+
+	return s
+}
+
+func (c *check) value(field string, val any, s *Schema) {
+	s = c.resolve(s)
+
+	// null is the same as leaving the field out
+	if s == nil || val == nil {
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(val, s.Enum) {
+		c.fail(field, "must be one of %s, got %v", formatEnum(s.Enum), val)
+
+		return
+	}
+
+	if s.IntOrString || s.Format == "int-or-string" {
+		switch val.(type) {
+		case int, string:
+		default:
+			c.fail(field, "expected integer or string, got %s", typeName(val))
+		}
+
+		return
+	}
+
+	switch s.Type {
+	case "object":
+		c.object(field, val, s)
+	case "array":
+		items, ok := val.([]any)
+		if !ok {
+			c.fail(field, "expected array, got %s", typeName(val))
+
+			return
+		}
+
+		for i, item := range items {
+			c.value(fmt.Sprintf("%s[%d]", field, i), item, s.Items)
+		}
+	case "string":
+		switch val.(type) {
+		case string, time.Time:
+		case int, float64:
+			if s.Format != "quantity" {
+				c.fail(field, "expected string, got %s", typeName(val))
+			}
+		default:
+			c.fail(field, "expected string, got %s", typeName(val))
+		}
+	case "integer":
+		if _, ok := val.(int); !ok {
+			c.fail(field, "expected integer, got %s", typeName(val))
+		}
+	case "number":
+		switch val.(type) {
+		case int, float64:
+		default:
+			c.fail(field, "expected number, got %s", typeName(val))
+		}
+	case "boolean":
+		if _, ok := val.(bool); !ok {
+			c.fail(field, "expected boolean, got %s", typeName(val))
+		}
+	case "":
+		if len(s.Properties) > 0 {
+			c.object(field, val, s)
+		}
+	}
+}
+
+func (c *check) object(field string, val any, s *Schema) {
+	obj, ok := val.(map[string]any)
+	if !ok {
+		c.fail(field, "expected object, got %s", typeName(val))
+
+		return
+	}
+
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			c.fail(join(field, name), "required field is missing")
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if prop, ok := s.Properties[k]; ok {
+			c.value(join(field, k), obj[k], prop)
+
+			continue
+		}
+
+		switch {
+		case s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil:
+			c.value(join(field, k), obj[k], s.AdditionalProperties.Schema)
+		case s.AdditionalProperties != nil && s.AdditionalProperties.Allowed:
+		case s.PreserveUnknownFields:
+		case len(s.Properties) == 0 && s.AdditionalProperties == nil:
+			// free form object
+		default:
+			c.fail(join(field, k), "unknown field")
+		}
+	}
+}
+
+func join(field, name string) string {
+	if field == "" {
+		return name
+	}
+
+	return field + "." + name
+}
+
+func typeName(val any) string {
+	switch val.(type) {
+	case string:
+		return "string"
+	case int:
+		return "integer"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", val)
+	}
+}
+
+func inEnum(val any, enum []any) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(val) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func formatEnum(enum []any) string {
+	out := make([]string, len(enum))
+	for i, e := range enum {
+		out[i] = fmt.Sprint(e)
+	}
+
+	return strings.Join(out, ", ")
+}
@@ -0,0 +1,196 @@
+package schema
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+spec:
+  group: example.com
+  names:
+    kind: Widget
+  versions:
+  - name: v1
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            required: [size]
+            properties:
+              size:
+                type: integer
+              colour:
+                type: string
+                enum: [red, green]
+`
+
+func TestValidateFile(t *testing.T) {
+	v, err := New()
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "widget.yaml"), []byte(testCRD), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := v.AddCRDs(dir); err != nil {
+		t.Fatalf("AddCRDs: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		manifest string
+		want     []string
+	}{
+		{
+			name: "valid deployment",
+			manifest: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  selector:
+    matchLabels:
+      app: api
+  template:
+    spec:
+      containers:
+      - name: api
+        image: api:abc1234
+        ports:
+        - containerPort: 8080
+        resources:
+          limits:
+            cpu: 1
+            memory: 128Mi
+`,
+			want: []string{},
+		},
+		{
+			name: "empty documents are skipped",
+			manifest: `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  KEY: value
+---
+`,
+			want: []string{},
+		},
+		{
+			name: "unknown field",
+			manifest: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+datas: {}
+`,
+			want: []string{"f.yaml: ConfigMap config: datas: unknown field"},
+		},
+		{
+			name: "wrong types",
+			manifest: `apiVersion: v1
+kind: Service
+metadata:
+  name: api
+spec:
+  ports:
+  - port: "80"
+    targetPort: true
+`,
+			want: []string{
+				"f.yaml: Service api: spec.ports[0].port: expected integer, got string",
+				"f.yaml: Service api: spec.ports[0].targetPort: expected integer or string, got boolean",
+			},
+		},
+		{
+			name: "crd enum",
+			manifest: `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec:
+  size: 3
+  colour: blue
+`,
+			want: []string{"f.yaml: Widget w: spec.colour: must be one of red, green, got blue"},
+		},
+		{
+			name: "missing kind",
+			manifest: `apiVersion: v1
+metadata:
+  name: api
+`,
+			want: []string{"f.yaml:  api: apiVersion and kind are required"},
+		},
+		{
+			name: "unknown kind",
+			manifest: `apiVersion: example.com/v2
+kind: Gadget
+metadata:
+  name: g
+`,
+			want: []string{"f.yaml: Gadget g: no schema for example.com/v2 Gadget, add its CRD to the gitops repo"},
+		},
+		{
+			name: "not an object",
+			manifest: `- a
+- b
+`,
+			want: []string{"f.yaml: document 0 is not an object"},
+		},
+		{
+			name: "crd",
+			manifest: `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec:
+  size: 3
+`,
+			want: []string{},
+		},
+		{
+			name: "crd required field",
+			manifest: `apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: w
+spec: {}
+`,
+			want: []string{"f.yaml: Widget w: spec.size: required field is missing"},
+		},
+		{
+			name:     "invalid yaml",
+			manifest: "a: [",
+			want:     []string{"f.yaml: yaml: line 1: did not find expected node content"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := v.ValidateFile("f.yaml", []byte(tt.manifest))
+
+			got := make([]string, len(problems))
+			for i, p := range problems {
+				got[i] = p.String()
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got problems %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+// Package secrets decrypts age encrypted values, seals them for the sealed
+// secrets controller, and finds the secrets in the gitops repo.
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// Decrypter decrypts age encrypted values
+type Decrypter struct {
+	identities []age.Identity
+}
+
+// NewDecrypter loads the age identities from the key file
+func NewDecrypter(keyFile string) (*Decrypter, error) {
+	if keyFile == "" {
+		return nil, errors.New("an age key file is required to decrypt secrets")
+	}
+
+	bts, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read age key file: %w", err)
+	}
+
+	ids, err := age.ParseIdentities(bytes.NewReader(bts))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse age key file: %w", err)
+	}
+
+	return &Decrypter{identities: ids}, nil
+}
+
+// Decrypt the armored value
+func (d *Decrypter) Decrypt(value string) ([]byte, error) {
+	r, err := age.Decrypt(armor.NewReader(strings.NewReader(strings.TrimSpace(value))), d.identities...)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
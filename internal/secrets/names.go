@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+)
+
+// Names of the secrets created by the manifests in dir: Secrets, and the
+// secrets SealedSecrets and ExternalSecrets create, keyed by SecretKey. A
+// missing dir has none.
+func Names(dir string) (map[string]bool, error) {
+	names := map[string]bool{}
+
+	if dir == "" {
+		return names, nil
+	}
+
+	err := filepath.WalkDir(dir, func(fp string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		switch filepath.Ext(fp) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+
+		bts, err := os.ReadFile(fp)
+		if err != nil {
+			return err
+		}
+
+		manifests, err := destination.ParseManifests(bts)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", fp, err)
+		}
+
+		for _, m := range manifests {
+			if name := SecretName(m); name != "" {
+				names[SecretKey(m.Namespace, name)] = true
+			}
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return names, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return names, nil
+}
+
+// SecretKey of a secret in the namespace. Secrets without a namespace are
+// created in the namespace they're applied to.
+func SecretKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// SecretName is the name of the secret the manifest creates, if any
+func SecretName(m *destination.Manifest) string {
+	obj := struct {
+		Spec struct {
+			Template struct {
+				Metadata struct {
+					Name string `json:"name"`
+				} `json:"metadata"`
+			} `json:"template"`
+			Target struct {
+				Name string `json:"name"`
+			} `json:"target"`
+		} `json:"spec"`
+	}{}
+
+	switch m.Kind {
+	case "Secret":
+		return m.Name
+	case "SealedSecret":
+		if err := json.Unmarshal(m.Raw, &obj); err == nil && obj.Spec.Template.Metadata.Name != "" {
+			return obj.Spec.Template.Metadata.Name
+		}
+
+		return m.Name
+	case "ExternalSecret":
+		if err := json.Unmarshal(m.Raw, &obj); err == nil && obj.Spec.Target.Name != "" {
+			return obj.Spec.Target.Name
+		}
+
+		return m.Name
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,96 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNames(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"prod/db.yaml": `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: prod
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-secret
+  namespace: prod
+`,
+		"prod/sealed.yaml": `apiVersion: bitnami.com/v1alpha1
+kind: SealedSecret
+metadata:
+  name: sealed
+  namespace: prod
+spec:
+  template:
+    metadata:
+      name: sealed-target
+`,
+		"staging/external.json": `{"apiVersion": "external-secrets.io/v1beta1", "kind": "ExternalSecret",
+  "metadata": {"name": "external", "namespace": "staging"}, "spec": {"target": {"name": "external-target"}}}`,
+		"shared.yml": `apiVersion: v1
+kind: Secret
+metadata:
+  name: shared
+`,
+		"README.md": "kind: Secret",
+	}
+
+	for name, data := range files {
+		fp := filepath.Join(dir, name)
+
+		if err := os.MkdirAll(filepath.Dir(fp), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(fp, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		dir  string
+		want map[string]bool
+	}{
+		{
+			name: "secrets by namespace",
+			dir:  dir,
+			want: map[string]bool{
+				"prod/db":                 true,
+				"prod/sealed-target":      true,
+				"staging/external-target": true,
+				"/shared":                 true,
+			},
+		},
+		{
+			name: "no dir",
+			want: map[string]bool{},
+		},
+		{
+			name: "missing dir",
+			dir:  filepath.Join(dir, "missing"),
+			want: map[string]bool{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Names(tt.dir)
+			if err != nil {
+				t.Fatalf("Names: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// sessionKeyBytes is the size of the aes key each value is encrypted with
+const sessionKeyBytes = 32
+
+// Sealer seals values for the sealed secrets controller
+type Sealer struct {
+	key *rsa.PublicKey
+}
+
+// NewSealer loads the controller's cert
+func NewSealer(certFile string) (*Sealer, error) {
+	if certFile == "" {
+		return nil, errors.New("the project has no sealed secrets cert")
+	}
+
+	bts, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sealed secrets cert: %w", err)
+	}
+
+	block, _ := pem.Decode(bts)
+	if block == nil {
+		return nil, fmt.Errorf("no pem data in %s", certFile)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sealed secrets cert: %w", err)
+	}
+
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("sealed secrets cert must have an rsa key")
+	}
+
+	return &Sealer{key: key}, nil
+}
+
+// Seal the value of the secret, strictly scoped to its namespace and name as
+// kubeseal does. The session key is encrypted with the controller's key, and
+// the value with the session key.
+func (s *Sealer) Seal(namespace, name string, value []byte) (string, error) {
+	sessionKey := make([]byte, sessionKeyBytes)
+
+	if _, err := rand.Read(sessionKey); err != nil {
+		return "", err
+	}
+
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	label := []byte(fmt.Sprintf("%s/%s", namespace, name))
+
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, s.key, sessionKey, label)
+	if err != nil {
+		return "", err
+	}
+
+	out := make([]byte, 2, 2+len(rsaCiphertext)+len(value)+gcm.Overhead())
+	binary.BigEndian.PutUint16(out, uint16(len(rsaCiphertext)))
+	out = append(out, rsaCiphertext...)
+
+	// the session key is only used once, so a zero nonce is safe
+	out = gcm.Seal(out, make([]byte, gcm.NonceSize()), value, nil)
+
+	return base64.StdEncoding.EncodeToString(out), nil
+}
@@ -0,0 +1,155 @@
+package secrets
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeCert(t *testing.T, pub, priv crypto.PublicKey) string {
+	t.Helper()
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sealed-secrets"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, pub, priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := filepath.Join(t.TempDir(), "cert.pem")
+
+	if err := os.WriteFile(p, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+// unseal a value as the sealed secrets controller does
+func unseal(key *rsa.PrivateKey, namespace, name, sealed string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	n := binary.BigEndian.Uint16(data)
+	rsaCiphertext, aesCiphertext := data[2:2+n], data[2+n:]
+
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, rsaCiphertext, []byte(namespace+"/"+name))
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return gcm.Open(nil, make([]byte, gcm.NonceSize()), aesCiphertext, nil)
+}
+
+func TestSeal(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealer, err := NewSealer(writeCert(t, &key.PublicKey, key))
+	if err != nil {
+		t.Fatalf("NewSealer: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		value         string
+		unsealAs      string
+		wantUnsealErr bool
+	}{
+		{name: "value", value: "hunter2", unsealAs: "api-1-db"},
+		{name: "empty value", value: "", unsealAs: "api-1-db"},
+		{name: "scoped to name", value: "hunter2", unsealAs: "api-2-db", wantUnsealErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := sealer.Seal("prod", "api-1-db", []byte(tt.value))
+			if err != nil {
+				t.Fatalf("Seal: %v", err)
+			}
+
+			got, err := unseal(key, "prod", tt.unsealAs, sealed)
+			if tt.wantUnsealErr {
+				if err == nil {
+					t.Error("unseal should fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unseal: %v", err)
+			}
+
+			if string(got) != tt.value {
+				t.Errorf("unsealed %q, want %q", got, tt.value)
+			}
+		})
+	}
+}
+
+func TestNewSealerErrors(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ecCert := writeCert(t, &ecKey.PublicKey, ecKey)
+
+	noPEM := filepath.Join(t.TempDir(), "cert.pem")
+	if err := os.WriteFile(noPEM, []byte("nope"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		certFile string
+	}{
+		{"no cert", ""},
+		{"missing cert", filepath.Join(t.TempDir(), "missing.pem")},
+		{"no pem data", noPEM},
+		{"not rsa", ecCert},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSealer(tt.certFile); err == nil {
+				t.Error("NewSealer should fail")
+			}
+		})
+	}
+}
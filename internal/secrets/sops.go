@@ -0,0 +1,411 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
+)
+
+// sopsKey is the key of the sops metadata in an encrypted file
+const sopsKey = "sops"
+
+var sopsValue = regexp.MustCompile(`^ENC\[AES256_GCM,data:(.+),iv:(.+),tag:(.+),type:(.+)\]`)
+
+// macOnlyEncryptedInit is hashed first when only encrypted values are in
+// the MAC, so the two kinds of MAC differ
+var macOnlyEncryptedInit = []byte{0x8a, 0x3f, 0xd2, 0xad, 0x54, 0xce, 0x66, 0x52, 0x7b, 0x10, 0x34, 0xf3, 0xd1, 0x47, 0xbe, 0xb, 0xb, 0x97, 0x5b, 0x3b, 0xf4, 0x4f, 0x72, 0xc6, 0xfd, 0xad, 0xec, 0x81, 0x76, 0xf2, 0x7d, 0x69}
+
+// IsSops returns true if the value was encrypted by sops
+func IsSops(value string) bool {
+	return strings.HasPrefix(value, "ENC[")
+}
+
+// sopsMetadata is the part of the sops metadata needed to decrypt a file
+// with the age backend
+type sopsMetadata struct {
+	Age []struct {
+		Recipient string `yaml:"recipient"`
+		Enc       string `yaml:"enc"`
+	} `yaml:"age"`
+
+	LastModified string `yaml:"lastmodified"`
+	MAC          string `yaml:"mac"`
+
+	UnencryptedSuffix       string `yaml:"unencrypted_suffix"`
+	EncryptedSuffix         string `yaml:"encrypted_suffix"`
+	UnencryptedRegex        string `yaml:"unencrypted_regex"`
+	EncryptedRegex          string `yaml:"encrypted_regex"`
+	UnencryptedCommentRegex string `yaml:"unencrypted_comment_regex"`
+	EncryptedCommentRegex   string `yaml:"encrypted_comment_regex"`
+	MACOnlyEncrypted        bool   `yaml:"mac_only_encrypted"`
+}
+
+// encrypted returns true if the value at the path is encrypted, by the
+// same rules sops encrypts with
+func (m *sopsMetadata) encrypted(path []string) (bool, error) {
+	encrypted := true
+
+	if m.UnencryptedSuffix != "" {
+		for _, p := range path {
+			if strings.HasSuffix(p, m.UnencryptedSuffix) {
+				encrypted = false
+
+				break
+			}
+		}
+	}
+
+	if m.EncryptedSuffix != "" {
+		encrypted = false
+
+		for _, p := range path {
+			if strings.HasSuffix(p, m.EncryptedSuffix) {
+				encrypted = true
+
+				break
+			}
+		}
+	}
+
+	if m.UnencryptedRegex != "" {
+		re, err := regexp.Compile(m.UnencryptedRegex)
+		if err != nil {
+			return false, err
+		}
+
+		for _, p := range path {
+			if re.MatchString(p) {
+				encrypted = false
+
+				break
+			}
+		}
+	}
+
+	if m.EncryptedRegex != "" {
+		re, err := regexp.Compile(m.EncryptedRegex)
+		if err != nil {
+			return false, err
+		}
+
+		encrypted = false
+
+		for _, p := range path {
+			if re.MatchString(p) {
+				encrypted = true
+
+				break
+			}
+		}
+	}
+
+	return encrypted, nil
+}
+
+// DecryptSops decrypts a yaml document encrypted by sops with the age
+// backend, checking it hasn't been tampered with. The decrypted document is
+// returned without its sops metadata.
+func (d *Decrypter) DecryptSops(data []byte) (*yaml.Node, error) {
+	doc := &yaml.Node{}
+
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("failed to parse sops file: %w", err)
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("sops file is not a yaml map")
+	}
+
+	root := doc.Content[0]
+
+	var meta *sopsMetadata
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != sopsKey {
+			continue
+		}
+
+		meta = &sopsMetadata{}
+
+		if err := root.Content[i+1].Decode(meta); err != nil {
+			return nil, fmt.Errorf("failed to parse sops metadata: %w", err)
+		}
+
+		root.Content = append(root.Content[:i:i], root.Content[i+2:]...)
+
+		break
+	}
+
+	if meta == nil {
+		return nil, errors.New("file has no sops metadata")
+	}
+
+	if meta.UnencryptedCommentRegex != "" || meta.EncryptedCommentRegex != "" {
+		return nil, errors.New("sops files encrypted by comment aren't supported")
+	}
+
+	key, err := d.sopsDataKey(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &sopsWalker{meta: meta, key: key, mac: sha512.New()}
+
+	if meta.MACOnlyEncrypted {
+		w.mac.Write(macOnlyEncryptedInit)
+	}
+
+	if err := w.walk(root, nil); err != nil {
+		return nil, err
+	}
+
+	if err := w.checkMAC(); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// sopsDataKey decrypts the file's data key with one of the age identities
+func (d *Decrypter) sopsDataKey(meta *sopsMetadata) ([]byte, error) {
+	if len(meta.Age) == 0 {
+		return nil, errors.New("only sops files encrypted with age are supported")
+	}
+
+	for _, a := range meta.Age {
+		r, err := age.Decrypt(armor.NewReader(strings.NewReader(a.Enc)), d.identities...)
+		if err != nil {
+			continue
+		}
+
+		return io.ReadAll(r)
+	}
+
+	return nil, errors.New("none of the age identities can decrypt the sops data key")
+}
+
+// sopsWalker decrypts the values of a sops file in place, hashing them in
+// the order sops does to check its MAC
+type sopsWalker struct {
+	meta *sopsMetadata
+	key  []byte
+	mac  hash.Hash
+}
+
+func (w *sopsWalker) walk(n *yaml.Node, path []string) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if err := w.walk(n.Content[i+1], append(path[:len(path):len(path)], n.Content[i].Value)); err != nil {
+				return err
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			if err := w.walk(item, path); err != nil {
+				return err
+			}
+		}
+	case yaml.AliasNode:
+		return errors.New("sops files with aliases aren't supported")
+	case yaml.ScalarNode:
+		return w.leaf(n, path)
+	}
+
+	return nil
+}
+
+func (w *sopsWalker) leaf(n *yaml.Node, path []string) error {
+	var v any
+
+	if err := n.Decode(&v); err != nil {
+		return err
+	}
+
+	// sops skips nulls entirely
+	if v == nil {
+		return nil
+	}
+
+	encrypted, err := w.meta.encrypted(path)
+	if err != nil {
+		return fmt.Errorf("invalid sops metadata: %w", err)
+	}
+
+	if !encrypted {
+		if w.meta.MACOnlyEncrypted {
+			return nil
+		}
+
+		b, err := macBytes(v)
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", strings.Join(path, "."), err)
+		}
+
+		w.mac.Write(b)
+
+		return nil
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("%s should be encrypted", strings.Join(path, "."))
+	}
+
+	if s == "" {
+		return nil
+	}
+
+	plain, datatype, err := decryptSopsValue(s, w.key, strings.Join(path, ":")+":")
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", strings.Join(path, "."), err)
+	}
+
+	b, value, tag, err := sopsTyped(plain, datatype)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", strings.Join(path, "."), err)
+	}
+
+	w.mac.Write(b)
+
+	n.Value, n.Tag, n.Style = value, tag, 0
+
+	return nil
+}
+
+func (w *sopsWalker) checkMAC() error {
+	modified, err := time.Parse(time.RFC3339, w.meta.LastModified)
+	if err != nil {
+		return fmt.Errorf("invalid sops lastmodified: %w", err)
+	}
+
+	mac, _, err := decryptSopsValue(w.meta.MAC, w.key, modified.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to decrypt sops mac: %w", err)
+	}
+
+	if string(mac) != fmt.Sprintf("%X", w.mac.Sum(nil)) {
+		return errors.New("sops mac doesn't match, the file has been tampered with")
+	}
+
+	return nil
+}
+
+// decryptSopsValue decrypts an ENC[AES256_GCM,...] value, returning the
+// plaintext and its type
+func decryptSopsValue(value string, key []byte, additionalData string) ([]byte, string, error) {
+	m := sopsValue.FindStringSubmatch(value)
+	if m == nil {
+		return nil, "", errors.New("not a sops encrypted value")
+	}
+
+	parts := make([][]byte, 3)
+
+	for i := range parts {
+		b, err := base64.StdEncoding.DecodeString(m[i+1])
+		if err != nil {
+			return nil, "", err
+		}
+
+		parts[i] = b
+	}
+
+	data, iv, tag := parts[0], parts[1], parts[2]
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, "", err
+	}
+
+	plain, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return plain, m[4], nil
+}
+
+// sopsTyped returns the bytes sops hashes for a decrypted value of the
+// type, and its yaml value and tag
+func sopsTyped(plain []byte, datatype string) ([]byte, string, string, error) {
+	s := string(plain)
+
+	switch datatype {
+	case "str", "bytes":
+		return plain, s, "!!str", nil
+	case "int":
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		return []byte(strconv.Itoa(i)), s, "!!int", nil
+	case "float":
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		return []byte(strconv.FormatFloat(f, 'f', -1, 64)), s, "!!float", nil
+	case "bool":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		v, _ := macBytes(b)
+
+		return v, strconv.FormatBool(b), "!!bool", nil
+	case "time":
+		t := time.Time{}
+		if err := t.UnmarshalText(plain); err != nil {
+			return nil, "", "", err
+		}
+
+		v, err := t.MarshalText()
+
+		return v, s, "!!timestamp", err
+	default:
+		return nil, "", "", fmt.Errorf("unknown sops type %s", datatype)
+	}
+}
+
+// macBytes is how sops hashes an unencrypted value
+func macBytes(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case string:
+		return []byte(v), nil
+	case int:
+		return []byte(strconv.Itoa(v)), nil
+	case float64:
+		return []byte(strconv.FormatFloat(v, 'f', -1, 64)), nil
+	case bool:
+		if v {
+			return []byte("True"), nil
+		}
+
+		return []byte("False"), nil
+	case time.Time:
+		return v.MarshalText()
+	default:
+		return nil, fmt.Errorf("unsupported type %T", v)
+	}
+}
@@ -0,0 +1,127 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"gopkg.in/yaml.v3"
+)
+
+// the fixtures in testdata/sops are plain.yaml encrypted by sops with the
+// age key in key.txt, e.g.
+//
+//	sops encrypt --age <recipient> --encrypted-regex '^sealed$' plain.yaml
+func TestDecryptSops(t *testing.T) {
+	d, err := NewDecrypter("testdata/sops/key.txt")
+	if err != nil {
+		t.Fatalf("NewDecrypter: %v", err)
+	}
+
+	plain, err := os.ReadFile("testdata/sops/plain.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want any
+	if err := yaml.Unmarshal(plain, &want); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		file    string
+		wantErr string
+	}{
+		// everything but keys with the default unencrypted_suffix
+		{file: "all.yaml"},
+		{file: "encrypted_regex.yaml"},
+		{file: "unencrypted_regex.yaml"},
+		{file: "encrypted_suffix.yaml"},
+		{file: "mac_only_encrypted.yaml"},
+
+		// an unencrypted value changed after encrypting
+		{file: "tampered.yaml", wantErr: "tampered with"},
+
+		// encrypted values swapped between keys
+		{file: "swapped.yaml", wantErr: "failed to decrypt name"},
+
+		{file: "plain.yaml", wantErr: "file has no sops metadata"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata/sops", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			doc, err := d.DecryptSops(data)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got error %v, want %s", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("DecryptSops: %v", err)
+			}
+
+			var got any
+			if err := doc.Decode(&got); err != nil {
+				t.Fatalf("failed to decode the decrypted file: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecryptSopsWrongKey(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyFile := filepath.Join(t.TempDir(), "key.txt")
+	if err := os.WriteFile(keyFile, []byte(id.String()+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := NewDecrypter(keyFile)
+	if err != nil {
+		t.Fatalf("NewDecrypter: %v", err)
+	}
+
+	data, err := os.ReadFile("testdata/sops/all.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.DecryptSops(data); err == nil || !strings.Contains(err.Error(), "none of the age identities") {
+		t.Errorf("got error %v, want none of the age identities", err)
+	}
+}
+
+func TestIsSops(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"ENC[AES256_GCM,data:itEuDA==,iv:aW8=,tag:dGFn,type:int]", true},
+		{"-----BEGIN AGE ENCRYPTED FILE-----", false},
+		{"plain", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsSops(tt.value); got != tt.want {
+			t.Errorf("IsSops(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
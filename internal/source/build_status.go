@@ -0,0 +1,82 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/registry"
+)
+
+// BuildStatusProvider looks up the build status of each service in a project
+// for a resolved ref.
+type BuildStatusProvider interface {
+	BuildStatus(ctx context.Context, proj *shipper.Project, ref *Ref) (*Builds, error)
+}
+
+// Builds are the build states of a project's services at a ref
+type Builds struct {
+	// Services with builds queued/running/completed
+	Services Services
+
+	// Indicates that builds are still running
+	Running bool
+
+	// Indicates that the services have all been built
+	Complete bool
+
+	// BuildNumber of the CI run, if the provider has one
+	BuildNumber int
+}
+
+// default pattern used to map a check or status name to a service
+var re = regexp.MustCompile(`(service\.[a-zA-Z0-9\-\.]+)`)
+
+// extractSvcName uses the first capture group of the pattern as the service
+// name, or the full match if the pattern has no groups.
+func extractSvcName(pattern *regexp.Regexp, checkName string) string {
+	submatch := pattern.FindStringSubmatch(checkName)
+	if len(submatch) == 0 {
+		return ""
+	}
+
+	if len(submatch) > 1 {
+		return submatch[1]
+	}
+
+	return submatch[0]
+}
+
+// newBuildStatusProvider picks the build status provider configured for the
+// project, defaulting to Github Actions.
+func newBuildStatusProvider(proj *shipper.Project, gh *GithubHelper) (BuildStatusProvider, error) {
+	switch proj.Build.Provider {
+	case "", "actions":
+		return &ActionsBuildStatus{gh: gh}, nil
+
+	case "statuses":
+		pattern := re
+
+		if proj.Build.StatusContextPattern != "" {
+			var err error
+
+			pattern, err = regexp.Compile(proj.Build.StatusContextPattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid build.statusContextPattern: %w", err)
+			}
+		}
+
+		return &StatusesBuildStatus{gh: gh, pattern: pattern}, nil
+
+	case "registry":
+		if proj.RegistryPrefix == "" {
+			return nil, fmt.Errorf("registryPrefix is required for the registry build provider")
+		}
+
+		return &RegistryBuildStatus{registry: registry.NewClient(), prefix: proj.RegistryPrefix}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown build provider '%s'", proj.Build.Provider)
+	}
+}
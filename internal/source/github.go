@@ -5,13 +5,12 @@ import (
 	"fmt"
 	"net/url"
 	"os"
-	"strings"
 
 	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/auth"
+	"github.com/cygnetdigital/shipper/internal/gitcache"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
-	"github.com/google/go-github/v45/github"
 	"golang.org/x/oauth2"
 )
 
@@ -24,40 +23,53 @@ type Github struct {
 	// gh *github.Client
 	gh *GithubHelper
 
-	gitAuth *http.BasicAuth
+	builds BuildStatusProvider
+
+	tokens oauth2.TokenSource
+
+	// cache of repo mirrors, or nil to clone every time
+	cache *gitcache.Cache
+
+	// tags pointing at commits which have been looked up, as a deploy gets
+	// the same commit until its builds are done
+	tags map[GitHash]string
 }
 
-// NewGithub sets up a new github source
-func NewGithub(proj *shipper.Project, accessToken string) *Github {
+// NewGithub sets up a new github source. The cache may be nil to clone the repo
+// from scratch every time.
+func NewGithub(proj *shipper.Project, creds auth.Credentials, cache *gitcache.Cache) (*Github, error) {
 	if proj.Repo == "" {
-		panic("project repo is required")
+		return nil, fmt.Errorf("project repo is required")
 	}
 
-	if !strings.Contains(proj.Repo, "github.com") {
-		panic("project repo must be a github repo")
+	owner, repo, err := auth.SplitRepo(proj.Repo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project repo: %w", err)
+	}
+
+	tokens := creds.TokenSource(owner, repo)
+
+	gh := &GithubHelper{
+		client: auth.NewGithubClient(tokens),
+		owner:  owner,
+		repo:   repo,
 	}
 
-	owner, repo, found := strings.Cut(strings.TrimPrefix(proj.Repo, "github.com/"), "/")
-	if !found {
-		panic("project repo must be of form 'github.com/org/repo'")
+	builds, err := newBuildStatusProvider(proj, gh)
+	if err != nil {
+		return nil, err
 	}
 
 	return &Github{
 		name:        proj.Name,
 		repo:        proj.Repo,
 		ensureClean: false,
-		gh: &GithubHelper{
-			client: github.NewClient(
-				oauth2.NewClient(
-					context.Background(),
-					oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken}),
-				),
-			),
-			owner: owner,
-			repo:  repo,
-		},
-		gitAuth: &http.BasicAuth{Username: "username", Password: accessToken},
-	}
+		gh:          gh,
+		builds:      builds,
+		tokens:      tokens,
+		cache:       cache,
+		tags:        map[GitHash]string{},
+	}, nil
 }
 
 // Get source from github
@@ -84,39 +96,95 @@ func (s *Github) Get(ctx context.Context, projectName string, ref string) (*Sour
 	}
 
 	// clone the repo and checkout the commit hash
-	repoPath, err := s.clone(resolvedRef.CommitHash)
-	if err != nil {
-		return nil, fmt.Errorf("failed to clone repo: %w", err)
-	}
+	repoPath, err := s.clone(ctx, resolvedRef.CommitHash)
 
 	// cleanup when ready
 	//nolint:errcheck
 	defer os.RemoveAll(repoPath)
 
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone repo: %w", err)
+	}
+
 	// load the project configuration at this commit
 	proj, err := shipper.LoadProject(repoPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get project context: %w", err)
 	}
 
-	// get the github checks for this commit
-	checks, err := s.gh.getWorkflowChecks(ctx, resolvedRef.CommitHash)
+	if usesTag(proj) {
+		if resolvedRef.Tag, err = s.tagForCommit(ctx, resolvedRef.CommitHash); err != nil {
+			return nil, err
+		}
+	}
+
+	// get the build status of each service for this commit
+	builds, err := s.builds.BuildStatus(ctx, proj, resolvedRef)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get checks: %w", err)
+		return nil, fmt.Errorf("failed to get build status: %w", err)
 	}
 
-	out.ChecksRunning = checks.Run.GetStatus() != "completed"
-	out.ChecksComplete = checks.Run.GetConclusion() == "success"
+	resolvedRef.BuildNumber = builds.BuildNumber
+
+	out.ChecksRunning = builds.Running
+	out.ChecksComplete = builds.Complete
 	out.Project = proj
-	out.Services, err = buildServices(proj, checks.Jobs)
+	out.Services = builds.Services
+
+	return out, nil
+}
+
+// tagForCommit looks up the tag pointing at the commit, once per commit
+func (s *Github) tagForCommit(ctx context.Context, hash GitHash) (string, error) {
+	if tag, ok := s.tags[hash]; ok {
+		return tag, nil
+	}
+
+	tag, err := s.gh.tagForCommit(ctx, hash)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build services: %w", err)
+		return "", err
 	}
 
-	return out, nil
+	s.tags[hash] = tag
+
+	return tag, nil
 }
 
-func (s *Github) clone(hash GitHash) (string, error) {
+// DefaultProject loads the project at the head of the repo's default branch.
+// Changes there are reviewed, so unlike a local copy it can be trusted for
+// service owners, approval policies and freeze windows.
+func (s *Github) DefaultProject(ctx context.Context, projectName string) (*shipper.Project, error) {
+	if projectName != s.name {
+		return nil, fmt.Errorf("project '%s' not setup", projectName)
+	}
+
+	hash, err := s.gh.defaultBranchHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	repoPath, err := s.clone(ctx, hash)
+
+	//nolint:errcheck
+	defer os.RemoveAll(repoPath)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone repo: %w", err)
+	}
+
+	proj, err := shipper.LoadProject(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project context: %w", err)
+	}
+
+	if proj.Name != projectName {
+		return nil, fmt.Errorf("project '%s' not found on the default branch", projectName)
+	}
+
+	return proj, nil
+}
+
+func (s *Github) clone(ctx context.Context, hash GitHash) (string, error) {
 	temp, err := os.MkdirTemp("", "dir")
 	if err != nil {
 		return "", err
@@ -131,15 +199,34 @@ func (s *Github) clone(hash GitHash) (string, error) {
 		uri.Scheme = "https"
 	}
 
-	repo, err := git.PlainClone(temp, false, &git.CloneOptions{
-		URL:           uri.String(),
-		Depth:         20,
-		Auth:          s.gitAuth,
-		ReferenceName: plumbing.ReferenceName("refs/heads/main"),
-		SingleBranch:  true,
-	})
+	gitAuth, err := auth.GitAuth(s.tokens)
 	if err != nil {
-		return "", fmt.Errorf("failed to clone repo: %w", err)
+		return "", err
+	}
+
+	var repo *git.Repository
+
+	if s.cache != nil {
+		mirror, err := s.cache.Mirror(ctx, uri.String(), gitAuth)
+		if err != nil {
+			return temp, err
+		}
+
+		repo, err = mirror.Worktree(temp)
+		if err != nil {
+			return temp, fmt.Errorf("failed to open worktree: %w", err)
+		}
+	} else {
+		repo, err = git.PlainClone(temp, false, &git.CloneOptions{
+			URL:           uri.String(),
+			Depth:         20,
+			Auth:          gitAuth,
+			ReferenceName: plumbing.ReferenceName("refs/heads/main"),
+			SingleBranch:  true,
+		})
+		if err != nil {
+			return temp, fmt.Errorf("failed to clone repo: %w", err)
+		}
 	}
 
 	commit, err := repo.CommitObject(plumbing.NewHash(string(hash)))
@@ -0,0 +1,148 @@
+package source
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/google/go-github/v45/github"
+)
+
+// ActionsBuildStatus reads build status from the jobs of the Github Actions
+// workflow run for a commit. Each job is mapped to a service by name.
+type ActionsBuildStatus struct {
+	gh *GithubHelper
+}
+
+// BuildStatus of the services in the project at the given ref
+func (a *ActionsBuildStatus) BuildStatus(ctx context.Context, proj *shipper.Project, ref *Ref) (*Builds, error) {
+	checks, err := a.gh.getWorkflowChecks(ctx, ref.CommitHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get checks: %w", err)
+	}
+
+	svcs, err := buildServices(proj, checks.Jobs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Builds{
+		Services:    svcs,
+		Running:     checks.Run.GetStatus() != "completed",
+		Complete:    checks.Run.GetConclusion() == "success",
+		BuildNumber: checks.Run.GetRunNumber(),
+	}, nil
+}
+
+func (g *GithubHelper) getWorkflowChecks(ctx context.Context, hash GitHash) (*workflowCheck, error) {
+	suites, _, err := g.client.Checks.ListCheckSuitesForRef(ctx, g.owner, g.repo, string(hash), &github.ListCheckSuiteOptions{
+		ListOptions: github.ListOptions{PerPage: 1000},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get check suites: %w", err)
+	}
+
+	if len(suites.CheckSuites) == 0 {
+		return nil, fmt.Errorf("no github check suites found for git hash %s", hash)
+	}
+
+	u := fmt.Sprintf("repos/%s/%s/actions/runs?check_suite_id=%d", g.owner, g.repo, suites.CheckSuites[0].GetID())
+
+	req, err := g.client.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	runs := new(github.WorkflowRuns)
+	if _, err := g.client.Do(ctx, req, &runs); err != nil {
+		return nil, err
+	}
+
+	if len(runs.WorkflowRuns) == 0 {
+		return nil, fmt.Errorf("no Github workflows ran for %s", hash)
+	}
+
+	run := runs.WorkflowRuns[0]
+
+	jobs, _, err := g.client.Actions.ListWorkflowJobs(ctx, g.owner, g.repo, run.GetID(), &github.ListWorkflowJobsOptions{
+		ListOptions: github.ListOptions{PerPage: 1000},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &workflowCheck{
+		Run:  run,
+		Jobs: jobs.Jobs,
+	}, nil
+}
+
+type workflowCheck struct {
+	Run  *github.WorkflowRun
+	Jobs []*github.WorkflowJob
+}
+
+func buildServices(proj *shipper.Project, jobs []*github.WorkflowJob) ([]*Service, error) {
+	svcs := make([]*Service, 0, len(proj.Services))
+
+	for _, svc := range proj.Services {
+		s := &Service{
+			Service: svc,
+		}
+
+		var seen bool
+
+		// go through each check and find the ones that match the service name
+		for _, job := range jobs {
+			if extractSvcName(re, job.GetName()) != s.Name {
+				continue
+			}
+
+			if seen {
+				return nil, fmt.Errorf("duplicate workflow jobs found for %s, and shipper only ever expected one per service", s.Name)
+			}
+
+			seen = true
+			s.BuildStatus = statusForJob(job)
+		}
+
+		if seen {
+			svcs = append(svcs, s)
+		}
+	}
+
+	return svcs, nil
+}
+
+//nolint
+func statusForJob(job *github.WorkflowJob) BuildStatus {
+	switch job.GetStatus() {
+	case "queued":
+		return &BuildStatusQueued{}
+
+	case "in_progress":
+		return &BuildStatusRunning{
+			StartedAt: job.GetStartedAt().Time,
+		}
+
+	case "completed":
+		c := job.GetConclusion()
+		switch c {
+		case "success":
+			return &BuildStatusComplete{
+				StartedAt:  job.GetStartedAt().Time,
+				FinishedAt: job.GetCompletedAt().Time,
+			}
+
+		default:
+			return &BuildStatusFailed{
+				Reason: fmt.Sprintf("job conclusion of '%s'", c),
+			}
+		}
+
+	default:
+		return &BuildStatusFailed{
+			Reason: "unknown job status",
+		}
+	}
+}
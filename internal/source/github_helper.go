@@ -3,10 +3,8 @@ package source
 import (
 	"context"
 	"fmt"
-	"regexp"
 	"strconv"
 
-	"github.com/cygnetdigital/shipper"
 	"github.com/google/go-github/v45/github"
 )
 
@@ -30,6 +28,7 @@ func (g *GithubHelper) Resolve(ctx context.Context, ref string) (*Ref, error) {
 			GivenRef:           "main",
 			CommitHash:         GitHash(branch.GetCommit().GetSHA()),
 			CommitedByUsername: branch.GetCommit().GetAuthor().GetLogin(),
+			Branch:             "main",
 		}, nil
 	}
 
@@ -57,6 +56,21 @@ func (g *GithubHelper) Resolve(ctx context.Context, ref string) (*Ref, error) {
 	return buildRefForPR(prs[0]), nil
 }
 
+// defaultBranchHead returns the commit at the head of the repo's default branch
+func (g *GithubHelper) defaultBranchHead(ctx context.Context) (GitHash, error) {
+	repo, _, err := g.client.Repositories.Get(ctx, g.owner, g.repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get repo: %w", err)
+	}
+
+	branch, _, err := g.client.Repositories.GetBranch(ctx, g.owner, g.repo, repo.GetDefaultBranch(), false)
+	if err != nil {
+		return "", fmt.Errorf("failed to get %s branch: %w", repo.GetDefaultBranch(), err)
+	}
+
+	return GitHash(branch.GetCommit().GetSHA()), nil
+}
+
 func buildRefForPR(pull *github.PullRequest) *Ref {
 	pr := &PullRequest{
 		Title:      pull.GetTitle(),
@@ -66,6 +80,9 @@ func buildRefForPR(pull *github.PullRequest) *Ref {
 		BaseCommit: *buildCommitForPRBranch(pull.GetBase()),
 		Merged:     pull.GetMerged(),
 		MergedAt:   pull.GetMergedAt(),
+
+		AuthorUsername: pull.GetUser().GetLogin(),
+		AuthorID:       pull.GetUser().GetID(),
 	}
 
 	if pr.Merged {
@@ -80,6 +97,7 @@ func buildRefForPR(pull *github.PullRequest) *Ref {
 		GivenRef:    fmt.Sprintf("Pull Request #%d", pull.GetID()),
 		PullRequest: pr,
 		CommitHash:  pr.MergeCommitHash,
+		Branch:      pr.HeadCommit.Ref,
 	}
 }
 
@@ -91,126 +109,27 @@ func buildCommitForPRBranch(branch *github.PullRequestBranch) *GithubCommit {
 	}
 }
 
-func (g *GithubHelper) getWorkflowChecks(ctx context.Context, hash GitHash) (*workflowCheck, error) {
-	suites, _, err := g.client.Checks.ListCheckSuitesForRef(ctx, g.owner, g.repo, string(hash), &github.ListCheckSuiteOptions{
-		ListOptions: github.ListOptions{PerPage: 1000},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get check suites: %w", err)
-	}
-
-	if len(suites.CheckSuites) == 0 {
-		return nil, fmt.Errorf("no github check suites found for git hash %s", hash)
-	}
-
-	u := fmt.Sprintf("repos/%s/%s/actions/runs?check_suite_id=%d", g.owner, g.repo, suites.CheckSuites[0].GetID())
-
-	req, err := g.client.NewRequest("GET", u, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	runs := new(github.WorkflowRuns)
-	if _, err := g.client.Do(ctx, req, &runs); err != nil {
-		return nil, err
-	}
-
-	if len(runs.WorkflowRuns) == 0 {
-		return nil, fmt.Errorf("no Github workflows ran for %s", hash)
-	}
-
-	run := runs.WorkflowRuns[0]
-
-	jobs, _, err := g.client.Actions.ListWorkflowJobs(ctx, g.owner, g.repo, run.GetID(), &github.ListWorkflowJobsOptions{
-		ListOptions: github.ListOptions{PerPage: 1000},
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return &workflowCheck{
-		Run:  run,
-		Jobs: jobs.Jobs,
-	}, nil
-}
-
-type workflowCheck struct {
-	Run  *github.WorkflowRun
-	Jobs []*github.WorkflowJob
-}
+// tagForCommit finds a tag pointing at the commit, returning an empty string
+// if there isn't one
+func (g *GithubHelper) tagForCommit(ctx context.Context, hash GitHash) (string, error) {
+	opts := &github.ListOptions{PerPage: 100}
 
-func buildServices(proj *shipper.Project, jobs []*github.WorkflowJob) ([]*Service, error) {
-	svcs := make([]*Service, 0, len(proj.Services))
-
-	for _, svc := range proj.Services {
-		s := &Service{
-			Service: svc,
+	for {
+		tags, resp, err := g.client.Repositories.ListTags(ctx, g.owner, g.repo, opts)
+		if err != nil {
+			return "", fmt.Errorf("failed to list tags: %w", err)
 		}
 
-		var seen bool
-
-		// go through each check and find the ones that match the service name
-		for _, job := range jobs {
-			if extractSvcName(job.GetName()) != s.Name {
-				continue
+		for _, t := range tags {
+			if GitHash(t.GetCommit().GetSHA()) == hash {
+				return t.GetName(), nil
 			}
-
-			if seen {
-				return nil, fmt.Errorf("duplicate workflow jobs found for %s, and shipper only ever expected one per service", s.Name)
-			}
-
-			seen = true
-			s.BuildStatus = statusForJob(job)
 		}
 
-		if seen {
-			svcs = append(svcs, s)
+		if resp.NextPage == 0 {
+			return "", nil
 		}
-	}
-
-	return svcs, nil
-}
-
-var re = regexp.MustCompile(`(service\.[a-zA-Z0-9\-\.]+)`)
 
-func extractSvcName(checkName string) string {
-	submatch := re.FindStringSubmatch(checkName)
-	if len(submatch) == 0 {
-		return ""
-	}
-
-	return submatch[0]
-}
-
-//nolint
-func statusForJob(job *github.WorkflowJob) BuildStatus {
-	switch job.GetStatus() {
-	case "queued":
-		return &BuildStatusQueued{}
-
-	case "in_progress":
-		return &BuildStatusRunning{
-			StartedAt: job.GetStartedAt().Time,
-		}
-
-	case "completed":
-		c := job.GetConclusion()
-		switch c {
-		case "success":
-			return &BuildStatusComplete{
-				StartedAt:  job.GetStartedAt().Time,
-				FinishedAt: job.GetCompletedAt().Time,
-			}
-
-		default:
-			return &BuildStatusFailed{
-				Reason: fmt.Sprintf("job conclusion of '%s'", c),
-			}
-		}
-
-	default:
-		return &BuildStatusFailed{
-			Reason: "unknown job status",
-		}
+		opts.Page = resp.NextPage
 	}
 }
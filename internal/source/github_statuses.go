@@ -0,0 +1,120 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/google/go-github/v45/github"
+)
+
+// StatusesBuildStatus reads build status from the combined commit statuses
+// reported by CI systems outside of Github Actions (e.g. Buildkite, Jenkins or
+// CircleCI). Each status context is mapped to a service using a pattern.
+type StatusesBuildStatus struct {
+	gh      *GithubHelper
+	pattern *regexp.Regexp
+}
+
+// BuildStatus of the services in the project at the given ref
+func (b *StatusesBuildStatus) BuildStatus(ctx context.Context, proj *shipper.Project, ref *Ref) (*Builds, error) {
+	combined, err := b.combinedStatus(ctx, ref.CommitHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(combined.Statuses) == 0 {
+		return nil, fmt.Errorf("no commit statuses found for git hash %s", ref.CommitHash)
+	}
+
+	svcs := make([]*Service, 0, len(proj.Services))
+
+	for _, svc := range proj.Services {
+		s := &Service{
+			Service: svc,
+		}
+
+		var seen bool
+
+		// go through each status and find the ones that match the service name
+		for _, status := range combined.Statuses {
+			if extractSvcName(b.pattern, status.GetContext()) != s.Name {
+				continue
+			}
+
+			if seen {
+				return nil, fmt.Errorf("duplicate status contexts found for %s, and shipper only ever expected one per service", s.Name)
+			}
+
+			seen = true
+			s.BuildStatus = statusForCommitStatus(status)
+		}
+
+		if seen {
+			svcs = append(svcs, s)
+		}
+	}
+
+	return &Builds{
+		Services: svcs,
+		Running:  combined.GetState() == "pending",
+		Complete: combined.GetState() == "success",
+	}, nil
+}
+
+// combinedStatus of the commit, with the statuses of every page
+func (b *StatusesBuildStatus) combinedStatus(ctx context.Context, hash GitHash) (*github.CombinedStatus, error) {
+	opts := &github.ListOptions{PerPage: 100}
+
+	var combined *github.CombinedStatus
+
+	for {
+		page, resp, err := b.gh.client.Repositories.GetCombinedStatus(ctx, b.gh.owner, b.gh.repo, string(hash), opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get commit statuses: %w", err)
+		}
+
+		if combined == nil {
+			combined = page
+		} else {
+			combined.Statuses = append(combined.Statuses, page.Statuses...)
+		}
+
+		if resp.NextPage == 0 {
+			return combined, nil
+		}
+
+		opts.Page = resp.NextPage
+	}
+}
+
+func statusForCommitStatus(status *github.RepoStatus) BuildStatus {
+	switch status.GetState() {
+	case "pending":
+		return &BuildStatusRunning{
+			StartedAt: status.GetCreatedAt(),
+		}
+
+	case "success":
+		return &BuildStatusComplete{
+			StartedAt:  status.GetCreatedAt(),
+			FinishedAt: status.GetUpdatedAt(),
+		}
+
+	case "failure", "error":
+		reason := status.GetDescription()
+		if reason == "" {
+			reason = fmt.Sprintf("status state of '%s'", status.GetState())
+		}
+
+		return &BuildStatusFailed{
+			Reason: reason,
+		}
+
+	default:
+		return &BuildStatusFailed{
+			Reason: "unknown status state",
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package source
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/cygnetdigital/shipper"
+)
+
+var defaultTagTemplate = "{{ .CommitHash.Short }}"
+
+// tagField matches templates which use the ref's git tag
+var tagField = regexp.MustCompile(`\.Tag\b`)
+
+// imageTag renders the image tag template for a service against the ref. The
+// service template takes precedence over the project one.
+func imageTag(proj *shipper.Project, svc *shipper.Service, ref *Ref) (string, error) {
+	tmpl := tagTemplate(proj, svc)
+
+	t, err := template.New("tag").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse tag template '%s': %w", tmpl, err)
+	}
+
+	out := &strings.Builder{}
+
+	if err := t.Execute(out, ref); err != nil {
+		return "", fmt.Errorf("failed to execute tag template '%s': %w", tmpl, err)
+	}
+
+	tag := strings.TrimSpace(out.String())
+	if tag == "" {
+		return "", fmt.Errorf("tag template '%s' rendered an empty tag", tmpl)
+	}
+
+	return tag, nil
+}
+
+func tagTemplate(proj *shipper.Project, svc *shipper.Service) string {
+	if svc.Build.Image.TagTemplate != "" {
+		return svc.Build.Image.TagTemplate
+	}
+
+	if proj.Image.TagTemplate != "" {
+		return proj.Image.TagTemplate
+	}
+
+	return defaultTagTemplate
+}
+
+// usesTag returns true if any service's image tag template uses the git tag,
+// which is expensive to look up
+func usesTag(proj *shipper.Project) bool {
+	for _, svc := range proj.Services {
+		if tagField.MatchString(tagTemplate(proj, svc)) {
+			return true
+		}
+	}
+
+	return false
+}
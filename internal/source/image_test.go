@@ -0,0 +1,43 @@
+package source
+
+import (
+	"testing"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/conf"
+)
+
+func TestUsesTag(t *testing.T) {
+	service := func(tmpl string) *shipper.Service {
+		return &shipper.Service{Service: &conf.Service{
+			Name:  "api",
+			Build: conf.ServiceBuild{Image: conf.ServiceImage{TagTemplate: tmpl}},
+		}}
+	}
+
+	tests := []struct {
+		name     string
+		project  string
+		services shipper.Services
+		want     bool
+	}{
+		{name: "default", services: shipper.Services{service("")}},
+		{name: "project template", project: "{{ .Tag }}", services: shipper.Services{service("")}, want: true},
+		{name: "overridden by the service", project: "{{ .Tag }}", services: shipper.Services{service("{{ .CommitHash.Short }}")}},
+		{name: "service template", services: shipper.Services{service(""), service(`{{ or .Tag "latest" }}`)}, want: true},
+		{name: "similar field", services: shipper.Services{service("{{ .Tags }}")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proj := &shipper.Project{
+				Project:  &conf.Project{Image: conf.ProjectImage{TagTemplate: tt.project}},
+				Services: tt.services,
+			}
+
+			if got := usesTag(proj); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/registry"
+)
+
+// RegistryBuildStatus treats a service as built once its image has been
+// pushed to the container registry, regardless of which pipeline built it.
+type RegistryBuildStatus struct {
+	registry *registry.Client
+	prefix   string
+}
+
+// BuildStatus of the services in the project at the given ref
+func (r *RegistryBuildStatus) BuildStatus(ctx context.Context, proj *shipper.Project, ref *Ref) (*Builds, error) {
+	out := &Builds{
+		Services: make([]*Service, 0, len(proj.Services)),
+		Complete: true,
+	}
+
+	for _, svc := range proj.Services {
+		image := path.Join(r.prefix, svc.ImageName())
+
+		tag, err := imageTag(proj, svc, ref)
+		if err != nil {
+			return nil, err
+		}
+
+		s := &Service{
+			Service:     svc,
+			BuildStatus: &BuildStatusComplete{},
+		}
+
+		if _, err := r.registry.Manifest(ctx, image, tag); err != nil {
+			if !errors.Is(err, registry.ErrNotFound) {
+				return nil, fmt.Errorf("failed to lookup image %s:%s: %w", image, tag, err)
+			}
+
+			s.BuildStatus = &BuildStatusQueued{}
+			out.Running = true
+			out.Complete = false
+		}
+
+		out.Services = append(out.Services, s)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,101 @@
+package source
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/conf"
+	"github.com/cygnetdigital/shipper/internal/registry"
+)
+
+func TestRegistryBuildStatus(t *testing.T) {
+	// only team/api and team/web-image have been pushed at abcdef1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/v2/team/api/manifests/abcdef1", "/v2/team/web-image/manifests/abcdef1":
+			w.Header().Set("Docker-Content-Digest", "sha256:0")
+		case "/v2/team/broken/manifests/abcdef1":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	rb := &RegistryBuildStatus{
+		registry: registry.NewClient(),
+		prefix:   strings.TrimPrefix(srv.URL, "http://") + "/team",
+	}
+
+	service := func(name, image string) *shipper.Service {
+		return &shipper.Service{Service: &conf.Service{
+			Name:  name,
+			Build: conf.ServiceBuild{Image: conf.ServiceImage{Name: image}},
+		}}
+	}
+
+	tests := []struct {
+		name         string
+		services     shipper.Services
+		wantStatus   []string
+		wantComplete bool
+		wantErr      bool
+	}{
+		{
+			name:         "all pushed",
+			services:     shipper.Services{service("api", ""), service("web", "web-image")},
+			wantStatus:   []string{BuildStatusComplete{}.String(), BuildStatusComplete{}.String()},
+			wantComplete: true,
+		},
+		{
+			name:         "not pushed yet",
+			services:     shipper.Services{service("api", ""), service("worker", "")},
+			wantStatus:   []string{BuildStatusComplete{}.String(), BuildStatusQueued{}.String()},
+			wantComplete: false,
+		},
+		{
+			name:     "registry error",
+			services: shipper.Services{service("broken", "")},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proj := &shipper.Project{Project: &conf.Project{}, Services: tt.services}
+
+			builds, err := rb.BuildStatus(context.Background(), proj, &Ref{CommitHash: "abcdef1234567890"})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("BuildStatus should fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("BuildStatus: %v", err)
+			}
+
+			if len(builds.Services) != len(tt.wantStatus) {
+				t.Fatalf("got %d services, want %d", len(builds.Services), len(tt.wantStatus))
+			}
+
+			for i, s := range builds.Services {
+				if s.BuildStatus.String() != tt.wantStatus[i] {
+					t.Errorf("%s is %s, want %s", s.Name, s.BuildStatus, tt.wantStatus[i])
+				}
+			}
+
+			if builds.Complete != tt.wantComplete || builds.Running == tt.wantComplete {
+				t.Errorf("got complete %v running %v, want complete %v", builds.Complete, builds.Running, tt.wantComplete)
+			}
+		})
+	}
+}
@@ -30,6 +30,11 @@ type Source struct {
 	ChecksComplete bool
 }
 
+// ImageTag for a service built from this source
+func (s *Source) ImageTag(svc *shipper.Service) (string, error) {
+	return imageTag(s.Project, svc, s.Ref)
+}
+
 // GitHash represents a git hash.
 type GitHash string
 
@@ -52,6 +57,16 @@ type Ref struct {
 	// username of person committed by
 	CommitedByUsername string
 
+	// Branch the commit was found on, or the head branch of a pull request
+	Branch string
+
+	// Tag pointing at the commit, if there is one. It's only looked up when an
+	// image tag template uses it.
+	Tag string
+
+	// BuildNumber of the CI run that built the commit, if known
+	BuildNumber int
+
 	// Details about the PullRequest
 	PullRequest *PullRequest
 }
@@ -64,6 +79,10 @@ type PullRequest struct {
 	HeadCommit GithubCommit
 	BaseCommit GithubCommit
 
+	// Author of the pull request, and their github user id
+	AuthorUsername string
+	AuthorID       int64
+
 	Merged           bool
 	MergedAt         time.Time
 	MergeCommitHash  GitHash
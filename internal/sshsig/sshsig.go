@@ -0,0 +1,159 @@
+// Package sshsig signs and verifies messages in the format used by git's
+// gpg.format=ssh, see PROTOCOL.sshsig in openssh
+package sshsig
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"hash"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Namespace git signs with, which signatures are checked against
+const Namespace = "git"
+
+const (
+	header = "-----BEGIN SSH SIGNATURE-----"
+	footer = "-----END SSH SIGNATURE-----"
+
+	// magic preamble of signatures, and of the data that's signed
+	magic = "SSHSIG"
+)
+
+// signature is the blob of an armored signature, after its magic preamble
+type signature struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// signedData is what the key signs, after the magic preamble
+type signedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// IsArmored returns true if the signature is an armored ssh signature
+func IsArmored(sig string) bool {
+	return strings.HasPrefix(strings.TrimSpace(sig), header)
+}
+
+// Sign the message with the key, returning an armored signature
+func Sign(signer ssh.Signer, message io.Reader) (string, error) {
+	h := sha512.New()
+	if _, err := io.Copy(h, message); err != nil {
+		return "", err
+	}
+
+	signed := append([]byte(magic), ssh.Marshal(&signedData{
+		Namespace:     Namespace,
+		HashAlgorithm: "sha512",
+		Hash:          h.Sum(nil),
+	})...)
+
+	var (
+		sig *ssh.Signature
+		err error
+	)
+
+	// rsa keys must use sha2 rather than the default sha1
+	if as, ok := signer.(ssh.AlgorithmSigner); ok && signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		sig, err = as.SignWithAlgorithm(rand.Reader, signed, ssh.SigAlgoRSASHA2512)
+	} else {
+		sig, err = signer.Sign(rand.Reader, signed)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	blob := append([]byte(magic), ssh.Marshal(&signature{
+		Version:       1,
+		PublicKey:     signer.PublicKey().Marshal(),
+		Namespace:     Namespace,
+		HashAlgorithm: "sha512",
+		Signature:     ssh.Marshal(sig),
+	})...)
+
+	enc := base64.StdEncoding.EncodeToString(blob)
+
+	out := &strings.Builder{}
+	out.WriteString(header + "\n")
+
+	for len(enc) > 70 {
+		out.WriteString(enc[:70] + "\n")
+		enc = enc[70:]
+	}
+
+	out.WriteString(enc + "\n" + footer + "\n")
+
+	return out.String(), nil
+}
+
+// Verify returns true if the armored signature of the message was made with
+// one of the keys
+func Verify(keys []ssh.PublicKey, message []byte, armored string) bool {
+	body := strings.TrimSpace(armored)
+	body = strings.TrimPrefix(body, header)
+	body = strings.TrimSuffix(body, footer)
+
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(body), ""))
+	if err != nil || !bytes.HasPrefix(blob, []byte(magic)) {
+		return false
+	}
+
+	sig := &signature{}
+
+	if err := ssh.Unmarshal(blob[len(magic):], sig); err != nil || sig.Version != 1 || sig.Namespace != Namespace {
+		return false
+	}
+
+	pub, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return false
+	}
+
+	var h hash.Hash
+
+	switch sig.HashAlgorithm {
+	case "sha512":
+		h = sha512.New()
+	case "sha256":
+		h = sha256.New()
+	default:
+		return false
+	}
+
+	h.Write(message)
+
+	signed := append([]byte(magic), ssh.Marshal(&signedData{
+		Namespace:     sig.Namespace,
+		Reserved:      sig.Reserved,
+		HashAlgorithm: sig.HashAlgorithm,
+		Hash:          h.Sum(nil),
+	})...)
+
+	s := &ssh.Signature{}
+	if err := ssh.Unmarshal(sig.Signature, s); err != nil {
+		return false
+	}
+
+	for _, k := range keys {
+		if bytes.Equal(k.Marshal(), pub.Marshal()) {
+			return pub.Verify(signed, s) == nil
+		}
+	}
+
+	return false
+}
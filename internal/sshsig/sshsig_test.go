@@ -0,0 +1,191 @@
+package sshsig
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func testSigner(t *testing.T, key any) ssh.Signer {
+	t.Helper()
+
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signer
+}
+
+func TestSign(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		signer  ssh.Signer
+		wantAlg string
+	}{
+		{
+			name:    "ed25519",
+			signer:  testSigner(t, edKey),
+			wantAlg: ssh.KeyAlgoED25519,
+		},
+		{
+			name:    "rsa uses sha2",
+			signer:  testSigner(t, rsaKey),
+			wantAlg: ssh.SigAlgoRSASHA2512,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := "tree abc\n\ndeploy\n"
+
+			armored, err := Sign(tt.signer, strings.NewReader(msg))
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+
+			if !strings.HasPrefix(armored, "-----BEGIN SSH SIGNATURE-----\n") ||
+				!strings.HasSuffix(armored, "\n-----END SSH SIGNATURE-----\n") {
+				t.Fatalf("signature isn't armored:\n%s", armored)
+			}
+
+			lines := strings.Split(strings.TrimSpace(armored), "\n")
+			for _, l := range lines[1 : len(lines)-1] {
+				if len(l) > 70 {
+					t.Errorf("armored line is %d chars", len(l))
+				}
+			}
+
+			blob, err := base64.StdEncoding.DecodeString(strings.Join(lines[1:len(lines)-1], ""))
+			if err != nil {
+				t.Fatalf("signature isn't base64: %v", err)
+			}
+
+			if !bytes.HasPrefix(blob, []byte("SSHSIG")) {
+				t.Fatal("signature has no SSHSIG preamble")
+			}
+
+			s := &signature{}
+			if err := ssh.Unmarshal(blob[6:], s); err != nil {
+				t.Fatalf("failed to parse signature: %v", err)
+			}
+
+			pub := tt.signer.PublicKey()
+
+			if s.Version != 1 || s.Namespace != "git" || s.HashAlgorithm != "sha512" {
+				t.Errorf("unexpected signature header %+v", s)
+			}
+
+			if !bytes.Equal(s.PublicKey, pub.Marshal()) {
+				t.Error("signature has the wrong public key")
+			}
+
+			sig := &ssh.Signature{}
+			if err := ssh.Unmarshal(s.Signature, sig); err != nil {
+				t.Fatalf("failed to parse signature: %v", err)
+			}
+
+			if sig.Format != tt.wantAlg {
+				t.Errorf("signed with %s, want %s", sig.Format, tt.wantAlg)
+			}
+
+			// the signed data, written out field by field as in PROTOCOL.sshsig
+			h := sha512.Sum512([]byte(msg))
+
+			signed := &bytes.Buffer{}
+			signed.WriteString("SSHSIG")
+
+			for _, f := range [][]byte{[]byte("git"), nil, []byte("sha512"), h[:]} {
+				signed.Write([]byte{0, 0, 0, byte(len(f))})
+				signed.Write(f)
+			}
+
+			if err := pub.Verify(signed.Bytes(), sig); err != nil {
+				t.Errorf("signature doesn't verify: %v", err)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signer, other := testSigner(t, key), testSigner(t, otherKey)
+	msg := []byte("shipper approval\n")
+
+	armored, err := Sign(signer, bytes.NewReader(msg))
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+
+	// a signature in another namespace, e.g. ssh-keygen -Y sign -n file
+	otherNamespace := func() string {
+		h := sha512.Sum512(msg)
+
+		sig, err := signer.Sign(rand.Reader, append([]byte(magic), ssh.Marshal(&signedData{
+			Namespace: "file", HashAlgorithm: "sha512", Hash: h[:],
+		})...))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		blob := append([]byte(magic), ssh.Marshal(&signature{
+			Version: 1, PublicKey: signer.PublicKey().Marshal(), Namespace: "file", HashAlgorithm: "sha512", Signature: ssh.Marshal(sig),
+		})...)
+
+		return header + "\n" + base64.StdEncoding.EncodeToString(blob) + "\n" + footer + "\n"
+	}()
+
+	tests := []struct {
+		name      string
+		keys      []ssh.PublicKey
+		message   []byte
+		signature string
+		want      bool
+	}{
+		{name: "valid", keys: []ssh.PublicKey{other.PublicKey(), signer.PublicKey()}, message: msg, signature: armored, want: true},
+		{name: "another key", keys: []ssh.PublicKey{other.PublicKey()}, message: msg, signature: armored},
+		{name: "no keys", message: msg, signature: armored},
+		{name: "another message", keys: []ssh.PublicKey{signer.PublicKey()}, message: []byte("shipper approval\nx"), signature: armored},
+		{name: "another namespace", keys: []ssh.PublicKey{signer.PublicKey()}, message: msg, signature: otherNamespace},
+		{name: "not base64", keys: []ssh.PublicKey{signer.PublicKey()}, message: msg, signature: header + "\n!!\n" + footer},
+		{name: "gpg", keys: []ssh.PublicKey{signer.PublicKey()}, message: msg, signature: "-----BEGIN PGP SIGNATURE-----"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.keys, tt.message, tt.signature); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !IsArmored(" \n" + armored) {
+		t.Error("armored signature isn't recognised")
+	}
+}
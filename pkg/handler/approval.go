@@ -0,0 +1,317 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cygnetdigital/shipper/internal/conf"
+	"github.com/cygnetdigital/shipper/internal/destination"
+)
+
+// Approver is implemented by destinations which store approval records
+type Approver interface {
+	Approve(ctx context.Context, project string, plan *destination.Approval, signoff *destination.ApprovalSignoff) error
+	GetApproval(ctx context.Context, project, id string) (*destination.Approval, error)
+}
+
+// Identity tells us who is running shipper, which teams they are in, and
+// which keys they sign with
+type Identity interface {
+	CurrentUser(ctx context.Context) (string, error)
+	IsTeamMember(ctx context.Context, team, user string) (bool, error)
+	VerifySignature(ctx context.Context, user string, message []byte, signature string) (bool, error)
+}
+
+// Signer signs approvals given by the identity
+type Signer interface {
+	Sign(message io.Reader) (string, error)
+}
+
+// ApprovalStatus of a plan which needs approving
+type ApprovalStatus struct {
+	PlanID   string
+	Approved bool
+
+	// Have and Need are the count of valid approvals, and how many are needed
+	Have int
+	Need int
+}
+
+// ApproveParams describe the release we wish to approve
+type ApproveParams struct {
+	Project string
+	Service string
+
+	// Version to be released, defaults to the latest deploy
+	Version string
+}
+
+// ApproveResp is the result of an approval
+type ApproveResp struct {
+	Approval *destination.Approval
+	Status   *ApprovalStatus
+}
+
+// Approve releasing a service as the current user, if the policy allows
+// them to. The approval is signed, and only counts while the signature
+// matches one of the user's keys.
+func (h *LocalHandler) Approve(ctx context.Context, p *ApproveParams) (*ApproveResp, error) {
+	approver, ok := h.Dest.(Approver)
+	if !ok {
+		return nil, fmt.Errorf("destination doesn't support approvals")
+	}
+
+	if h.Identity == nil {
+		return nil, fmt.Errorf("there is no identity to approve with")
+	}
+
+	if h.Signer == nil {
+		return nil, fmt.Errorf("approvals must be signed, but there is no signing key")
+	}
+
+	rule := h.approvalRule(p.Service)
+	if requiredApprovals(rule) == 0 {
+		return nil, fmt.Errorf("%s doesn't need approval", p.Service)
+	}
+
+	dest, err := h.Dest.Get(ctx, p.Project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get destination: %w", err)
+	}
+
+	svc := dest.Services.Lookup(p.Service)
+	if svc == nil {
+		return nil, fmt.Errorf("service not found")
+	}
+
+	version := p.Version
+	if version == "" {
+		if len(svc.Deploys) == 0 {
+			return nil, fmt.Errorf("no deploys found for service")
+		}
+
+		version = svc.Deploys[len(svc.Deploys)-1].Version
+	}
+
+	if !svc.HasVersion(version) {
+		return nil, fmt.Errorf("version %s not found", version)
+	}
+
+	if svc.CurrentReleaseVersion == version {
+		return nil, fmt.Errorf("%s is already at %s", p.Service, version)
+	}
+
+	user, err := h.Identity.CurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err = h.canApprove(ctx, rule, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("%s is not an approver for %s", user, p.Service)
+	}
+
+	a, err := getApproval(ctx, approver, releaseApproval(p.Project, p.Service, version, svc.CurrentReleaseVersion))
+	if err != nil {
+		return nil, err
+	}
+
+	signoff := &destination.ApprovalSignoff{By: user, At: time.Now().UTC().Truncate(time.Second)}
+	payload := a.SignoffPayload(signoff)
+
+	if signoff.Signature, err = h.Signer.Sign(bytes.NewReader(payload)); err != nil {
+		return nil, fmt.Errorf("failed to sign approval: %w", err)
+	}
+
+	ok, err = h.Identity.VerifySignature(ctx, user, payload, signoff.Signature)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, fmt.Errorf("the signing key isn't one of the ssh or gpg keys on %s's github account", user)
+	}
+
+	if err := approver.Approve(ctx, p.Project, a, signoff); err != nil {
+		return nil, fmt.Errorf("failed to approve: %w", err)
+	}
+
+	a.Approvals = append(a.Approvals, signoff)
+
+	// the releaser isn't known yet, so no one is excluded
+	status, err := h.approvalStatus(ctx, rule, a, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &ApproveResp{Approval: a, Status: status}, nil
+}
+
+// checkApproval returns the approval status of a release if the service
+// needs approving, or nil if no approval is needed
+func (h *LocalHandler) checkApproval(ctx context.Context, project, service, version, from string) (*ApprovalStatus, error) {
+	rule := h.approvalRule(service)
+	if requiredApprovals(rule) == 0 {
+		return nil, nil
+	}
+
+	approver, ok := h.Dest.(Approver)
+	if !ok {
+		return nil, fmt.Errorf("%s needs approval, but the destination doesn't support approvals", service)
+	}
+
+	if h.Identity == nil {
+		return nil, fmt.Errorf("%s needs approval, but there is no identity to approve with", service)
+	}
+
+	user, err := h.Identity.CurrentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := getApproval(ctx, approver, releaseApproval(project, service, version, from))
+	if err != nil {
+		return nil, err
+	}
+
+	return h.approvalStatus(ctx, rule, a, user)
+}
+
+// getApproval returns the approval record of the plan, or the plan itself
+// if nothing has been approved yet. Records which don't match the plan are
+// rejected, so approvals can't be copied from another release.
+func getApproval(ctx context.Context, approver Approver, plan *destination.Approval) (*destination.Approval, error) {
+	a, err := approver.GetApproval(ctx, plan.Project, plan.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get approval: %w", err)
+	}
+
+	if a == nil {
+		return plan, nil
+	}
+
+	if !a.Matches(plan) {
+		return nil, fmt.Errorf("approval %s isn't for releasing %s %s from '%s'", plan.ID, plan.Service, plan.Version, plan.From)
+	}
+
+	return a, nil
+}
+
+// approvalStatus counts the signed approvals allowed by the rule. The
+// releaser may not approve their own release, unless the rule allows it.
+func (h *LocalHandler) approvalStatus(ctx context.Context, rule *conf.ApprovalRule, a *destination.Approval, releaser string) (*ApprovalStatus, error) {
+	status := &ApprovalStatus{PlanID: a.ID, Need: requiredApprovals(rule)}
+
+	for _, s := range a.Approvals {
+		if s.Signature == "" || (!rule.AllowSelfApproval && s.By == releaser) {
+			continue
+		}
+
+		ok, err := h.canApprove(ctx, rule, s.By)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		ok, err = h.Identity.VerifySignature(ctx, s.By, a.SignoffPayload(s), s.Signature)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			status.Have++
+		}
+	}
+
+	status.Approved = status.Have >= status.Need
+
+	return status, nil
+}
+
+// canApprove returns true if the user is one of the rule's approvers or in
+// one of its teams. Anyone can approve if the rule names no one.
+func (h *LocalHandler) canApprove(ctx context.Context, rule *conf.ApprovalRule, user string) (bool, error) {
+	if len(rule.Approvers) == 0 && len(rule.Teams) == 0 {
+		return true, nil
+	}
+
+	return h.isMember(ctx, append(append([]string{}, rule.Approvers...), rule.Teams...), user)
+}
+
+// isMember returns true if the user is one of the members, which are github
+// logins or org/team-slug teams
+func (h *LocalHandler) isMember(ctx context.Context, members []string, user string) (bool, error) {
+	for _, m := range members {
+		if strings.Contains(m, "/") {
+			ok, err := h.Identity.IsTeamMember(ctx, m, user)
+			if err != nil {
+				return false, err
+			}
+
+			if ok {
+				return true, nil
+			}
+
+			continue
+		}
+
+		if strings.EqualFold(m, user) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (h *LocalHandler) approvalRule(service string) *conf.ApprovalRule {
+	if rule, ok := h.Approval.Services[service]; ok {
+		return &rule
+	}
+
+	return &h.Approval.ApprovalRule
+}
+
+func requiredApprovals(rule *conf.ApprovalRule) int {
+	if rule.Required > 0 {
+		return rule.Required
+	}
+
+	if len(rule.Approvers) > 0 || len(rule.Teams) > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// releaseApproval is the approval record of a release, before any signoffs
+func releaseApproval(project, service, version, from string) *destination.Approval {
+	return &destination.Approval{
+		ID:        releasePlanID(project, service, version, from),
+		Operation: "release",
+		Project:   project,
+		Service:   service,
+		Version:   version,
+		From:      from,
+	}
+}
+
+// releasePlanID identifies a release, so approvals only apply to moving the
+// service between the same versions
+func releasePlanID(project, service, version, from string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{"release", project, service, from, version}, "\n")))
+
+	return hex.EncodeToString(sum[:])[:12]
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cygnetdigital/shipper/internal/conf"
+	"github.com/cygnetdigital/shipper/internal/destination"
+)
+
+// approvalDest stores approval records by id
+type approvalDest struct {
+	Destination
+	approvals map[string]*destination.Approval
+}
+
+func (d *approvalDest) Approve(ctx context.Context, project string, plan *destination.Approval, signoff *destination.ApprovalSignoff) error {
+	return nil
+}
+
+func (d *approvalDest) GetApproval(ctx context.Context, project, id string) (*destination.Approval, error) {
+	return d.approvals[id], nil
+}
+
+// testIdentity accepts signatures of the form "<user>:<message>"
+type testIdentity struct {
+	user string
+}
+
+func (i *testIdentity) CurrentUser(ctx context.Context) (string, error) {
+	return i.user, nil
+}
+
+func (i *testIdentity) IsTeamMember(ctx context.Context, team, user string) (bool, error) {
+	return false, nil
+}
+
+func (i *testIdentity) VerifySignature(ctx context.Context, user string, message []byte, signature string) (bool, error) {
+	return signature == user+":"+string(message), nil
+}
+
+func signedApproval(a *destination.Approval, by ...string) *destination.Approval {
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, user := range by {
+		s := &destination.ApprovalSignoff{By: user, At: at}
+		s.Signature = user + ":" + string(a.SignoffPayload(s))
+		a.Approvals = append(a.Approvals, s)
+	}
+
+	return a
+}
+
+func TestCheckApproval(t *testing.T) {
+	plan := releaseApproval("shop", "api", "v10", "v9")
+	old := releaseApproval("shop", "api", "v9", "v8")
+
+	tests := []struct {
+		name      string
+		approvals map[string]*destination.Approval
+		wantHave  int
+		wantOK    bool
+		wantErr   bool
+	}{
+		{
+			name: "no approvals",
+		},
+		{
+			name:      "approved",
+			approvals: map[string]*destination.Approval{plan.ID: signedApproval(releaseApproval("shop", "api", "v10", "v9"), "alice")},
+			wantHave:  1,
+			wantOK:    true,
+		},
+		{
+			name:      "self approval doesn't count",
+			approvals: map[string]*destination.Approval{plan.ID: signedApproval(releaseApproval("shop", "api", "v10", "v9"), "bob")},
+		},
+		{
+			name:      "non approver doesn't count",
+			approvals: map[string]*destination.Approval{plan.ID: signedApproval(releaseApproval("shop", "api", "v10", "v9"), "mallory")},
+		},
+		{
+			name: "bad signature doesn't count",
+			approvals: map[string]*destination.Approval{plan.ID: func() *destination.Approval {
+				a := signedApproval(releaseApproval("shop", "api", "v10", "v9"), "alice")
+				a.Approvals[0].Signature = "forged"
+
+				return a
+			}()},
+		},
+		{
+			name:      "replayed from an earlier release",
+			approvals: map[string]*destination.Approval{plan.ID: signedApproval(old, "alice")},
+			wantErr:   true,
+		},
+		{
+			name: "record for another version",
+			approvals: map[string]*destination.Approval{plan.ID: func() *destination.Approval {
+				a := releaseApproval("shop", "api", "v9", "v8")
+				a.ID = plan.ID
+
+				return signedApproval(a, "alice")
+			}()},
+			wantErr: true,
+		},
+		{
+			name: "record for another service",
+			approvals: map[string]*destination.Approval{plan.ID: func() *destination.Approval {
+				a := releaseApproval("shop", "web", "v10", "v9")
+				a.ID = plan.ID
+
+				return signedApproval(a, "alice")
+			}()},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &LocalHandler{
+				Dest:     &approvalDest{approvals: tt.approvals},
+				Identity: &testIdentity{user: "bob"},
+				Approval: conf.ProjectApproval{ApprovalRule: conf.ApprovalRule{Approvers: []string{"alice", "bob"}}},
+			}
+
+			status, err := h.checkApproval(context.Background(), "shop", "api", "v10", "v9")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("checkApproval should fail")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("checkApproval: %v", err)
+			}
+
+			if status.PlanID != plan.ID || status.Need != 1 {
+				t.Errorf("unexpected status %+v", status)
+			}
+
+			if status.Have != tt.wantHave || status.Approved != tt.wantOK {
+				t.Errorf("got %d approvals (approved %v), want %d (approved %v)", status.Have, status.Approved, tt.wantHave, tt.wantOK)
+			}
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cygnetdigital/shipper/internal/auth"
 	"github.com/cygnetdigital/shipper/internal/destination"
+	"github.com/cygnetdigital/shipper/internal/policy"
 	"github.com/cygnetdigital/shipper/internal/source"
 )
 
@@ -18,6 +20,13 @@ type DeployParams struct {
 
 	// Confirm params should be provided if we are confirming a deploy
 	Confirm *ConfirmDeployParams
+
+	// OverrideFreeze is the reason for deploying while services are frozen
+	OverrideFreeze string
+
+	// ChecksOnly returns once the source checks are complete without
+	// planning the deploy, so the project can be locked before it's planned
+	ChecksOnly bool
 }
 
 // ConfirmDeployParams are the params required to perform the deploy
@@ -31,6 +40,12 @@ type DeployResp struct {
 	Source   *source.Source
 	Services []*ServiceDeployStatus
 	Complete bool
+
+	// Policy rules the planned deploy breaks
+	Policy []*policy.Result
+
+	// Config values the planned deploy changes
+	Config []*destination.ConfigChange
 }
 
 // ServiceDeployRequest ...
@@ -58,23 +73,43 @@ func (h *LocalHandler) Deploy(ctx context.Context, p *DeployParams) (*DeployResp
 		return nil, err
 	}
 
-	if !source.ChecksComplete {
+	if !source.ChecksComplete || (p.ChecksOnly && p.Confirm == nil) {
 		return &DeployResp{Source: source, Services: svcs}, nil
 	}
 
 	// no confirm yet, so don't do the deploy
 	if p.Confirm == nil {
-		dest, err := h.Dest.Get(ctx, p.ProjectName)
+		names := []string{}
+		for _, s := range svcs {
+			names = append(names, s.Name)
+		}
+
+		if err := h.checkFreeze(ctx, p.ProjectName, names, p.OverrideFreeze); err != nil {
+			return nil, err
+		}
+
+		// versions are left to the plan, which reads them from the destination
+		requests := []*ServiceDeployRequest{}
+		for _, s := range svcs {
+			requests = append(requests, &ServiceDeployRequest{ServiceName: s.Name})
+		}
+
+		depreq, err := deployRequest(source, p, requests)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get destination: %w", err)
+			return nil, err
 		}
 
-		svcs, err := mapServices(source, dest)
+		plan, err := h.planDeploy(ctx, depreq)
+		if err != nil {
+			return nil, fmt.Errorf("failed to plan deploy: %w", err)
+		}
+
+		svcs, err := mapServices(source, plan.Destination)
 		if err != nil {
 			return nil, err
 		}
 
-		return &DeployResp{Source: source, Services: svcs}, nil
+		return &DeployResp{Source: source, Services: svcs, Policy: plan.Policy, Config: plan.Config}, nil
 	}
 
 	// Check the confirm git hash lines up
@@ -82,32 +117,70 @@ func (h *LocalHandler) Deploy(ctx context.Context, p *DeployParams) (*DeployResp
 		return nil, fmt.Errorf("source git hash %s does not match confirm git hash %s", source.Ref.CommitHash, p.Confirm.CommitHash)
 	}
 
+	names := []string{}
+	for _, creq := range p.Confirm.Requests {
+		names = append(names, creq.ServiceName)
+	}
+
+	if err := h.checkFreeze(ctx, p.ProjectName, names, p.OverrideFreeze); err != nil {
+		return nil, err
+	}
+
+	depreq, err := deployRequest(source, p, p.Confirm.Requests)
+	if err != nil {
+		return nil, err
+	}
+
+	depreq.LockToken = h.lockToken
+
+	if _, err := h.Dest.Deploy(ctx, depreq); err != nil {
+		return nil, fmt.Errorf("failed to deploy destination: %w", err)
+	}
+
+	return &DeployResp{
+		Source:   source,
+		Complete: true,
+	}, nil
+}
+
+// deployRequest for the destination to deploy the requested services
+func deployRequest(source *source.Source, p *DeployParams, requests []*ServiceDeployRequest) (*destination.DeployParams, error) {
 	depreq := &destination.DeployParams{
-		ProjectName: p.ProjectName,
-		Services:    []*destination.ServiceDeployParams{},
+		ProjectName:    p.ProjectName,
+		Services:       []*destination.ServiceDeployParams{},
+		FreezeOverride: p.OverrideFreeze,
 	}
 
-	for _, creq := range p.Confirm.Requests {
+	if pr := source.Ref.PullRequest; pr != nil {
+		depreq.PullRequest = pr.URL
+
+		if pr.AuthorUsername != "" {
+			depreq.CoAuthors = []*destination.CoAuthor{{
+				Login: pr.AuthorUsername,
+				Email: auth.NoReplyEmail(pr.AuthorID, pr.AuthorUsername),
+			}}
+		}
+	}
+
+	for _, creq := range requests {
 		svc := source.Services.Lookup(creq.ServiceName)
 		if svc == nil {
 			return nil, fmt.Errorf("service %s not found in source", creq.ServiceName)
 		}
 
+		tag, err := source.ImageTag(svc.Service)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build image tag for %s: %w", svc.Name, err)
+		}
+
 		depreq.Services = append(depreq.Services, &destination.ServiceDeployParams{
 			Config:   svc.Service,
 			Version:  creq.DeployVersion,
-			ImageTag: source.Ref.CommitHash.Short(),
+			ImageTag: tag,
 		})
 	}
 
-	if _, err := h.Dest.Deploy(ctx, depreq); err != nil {
-		return nil, fmt.Errorf("failed to deploy destination: %w", err)
-	}
-
-	return &DeployResp{
-		Source:   source,
-		Complete: true,
-	}, nil
+	return depreq, nil
 }
 
 func mapServices(source *source.Source, dest *destination.Destination) ([]*ServiceDeployStatus, error) {
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"github.com/cygnetdigital/shipper/internal/freeze"
+)
+
+// Freezer is implemented by destinations which store ad-hoc service freezes
+type Freezer interface {
+	Freeze(ctx context.Context, project string, f *destination.Freeze) error
+	Unfreeze(ctx context.Context, project, service string) error
+	GetFreezes(ctx context.Context, project string) ([]*destination.Freeze, error)
+}
+
+// FrozenError is returned when a deploy or release is refused by a freeze
+type FrozenError struct {
+	Service string
+
+	// Name of the freeze window, or who froze the service
+	By     string
+	Reason string
+}
+
+func (e *FrozenError) Error() string {
+	msg := fmt.Sprintf("%s is frozen by %s", e.Service, e.By)
+
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+
+	return msg + " (use --override-freeze with a reason to continue)"
+}
+
+// checkFreeze refuses if any of the services are frozen by a window in the
+// project config or an ad-hoc freeze in the destination, unless there is a
+// reason to override it.
+func (h *LocalHandler) checkFreeze(ctx context.Context, project string, services []string, override string) error {
+	if override != "" {
+		return nil
+	}
+
+	now := time.Now()
+
+	for i := range h.Freezes {
+		f := &h.Freezes[i]
+
+		active, err := freeze.Active(f, now)
+		if err != nil {
+			return err
+		}
+
+		if !active {
+			continue
+		}
+
+		for _, svc := range services {
+			if freeze.Applies(f, svc) {
+				return &FrozenError{Service: svc, By: fmt.Sprintf("window %s", f.Name), Reason: f.Reason}
+			}
+		}
+	}
+
+	freezer, ok := h.Dest.(Freezer)
+	if !ok {
+		return nil
+	}
+
+	freezes, err := freezer.GetFreezes(ctx, project)
+	if err != nil {
+		return fmt.Errorf("failed to get freezes: %w", err)
+	}
+
+	for _, f := range freezes {
+		for _, svc := range services {
+			if f.Service == svc {
+				return &FrozenError{Service: svc, By: f.By, Reason: f.Reason}
+			}
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,8 @@ package handler
 import (
 	"context"
 
+	"github.com/cygnetdigital/shipper"
+	"github.com/cygnetdigital/shipper/internal/conf"
 	"github.com/cygnetdigital/shipper/internal/destination"
 	"github.com/cygnetdigital/shipper/internal/source"
 )
@@ -12,6 +14,23 @@ import (
 type LocalHandler struct {
 	Source SourceGetter
 	Dest   Destination
+
+	// Freezes from the project config, refusing deploys and releases
+	Freezes []conf.ProjectFreeze
+
+	// Approval policy for releases, checked using the identity. Approvals
+	// given by the identity are signed with the signer.
+	Approval conf.ProjectApproval
+	Identity Identity
+	Signer   Signer
+
+	// Services configured on the default branch, and whether only their
+	// owners may release or remove them
+	Services  shipper.Services
+	Ownership conf.ProjectOwnership
+
+	// lockToken of the project lock held by this run, see Lock
+	lockToken string
 }
 
 // SourceGetter allows us to get source for a project/ref
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+)
+
+// Locker is implemented by destinations which can lock a project, so that
+// concurrent operations are serialized
+type Locker interface {
+	Lock(ctx context.Context, project, holder, token, operation string, ttl time.Duration) (*destination.Lock, error)
+	Unlock(ctx context.Context, project, token string, force bool) error
+	GetLock(ctx context.Context, project string) (*destination.Lock, error)
+}
+
+// LockParams describe the lock to acquire
+type LockParams struct {
+	Project   string
+	Holder    string
+	Operation string
+	TTL       time.Duration
+}
+
+// Lock acquires the project lock before planning an operation. The returned
+// func releases it once the operation has been pushed. Changes pushed by the
+// handler while locked fail if the lock has been lost. Destinations that
+// can't lock are left unlocked.
+func (h *LocalHandler) Lock(ctx context.Context, p *LockParams) (func(context.Context) error, error) {
+	locker, ok := h.Dest.(Locker)
+	if !ok {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := locker.Lock(ctx, p.Project, p.Holder, token, p.Operation, p.TTL); err != nil {
+		return nil, err
+	}
+
+	h.lockToken = token
+
+	return func(ctx context.Context) error {
+		h.lockToken = ""
+
+		if err := locker.Unlock(ctx, p.Project, token, false); err != nil {
+			return fmt.Errorf("failed to unlock %s: %w", p.Project, err)
+		}
+
+		return nil
+	}, nil
+}
+
+// newLockToken identifies a run's lock, so a concurrent run by the same
+// holder can't take or release it
+func newLockToken() (string, error) {
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
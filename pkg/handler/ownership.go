@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// serviceOwners returns the owners of the service from the handler's
+// services, which come from the trusted default branch config
+func (h *LocalHandler) serviceOwners(service string) []string {
+	for _, svc := range h.Services {
+		if svc.Name == service {
+			return svc.Owners
+		}
+	}
+
+	return nil
+}
+
+// checkOwner refuses unless the current user owns the service, when
+// ownership is enforced. Members of the break glass group are let through,
+// and their login is returned so it can be recorded.
+func (h *LocalHandler) checkOwner(ctx context.Context, service string) (string, error) {
+	if !h.Ownership.Enforce {
+		return "", nil
+	}
+
+	owners := h.serviceOwners(service)
+	if len(owners) == 0 {
+		return "", nil
+	}
+
+	if h.Identity == nil {
+		return "", fmt.Errorf("ownership of %s is enforced, but there is no identity to check", service)
+	}
+
+	user, err := h.Identity.CurrentUser(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	ok, err := h.isMember(ctx, owners, user)
+	if err != nil {
+		return "", err
+	}
+
+	if ok {
+		return "", nil
+	}
+
+	ok, err = h.isMember(ctx, h.Ownership.BreakGlass, user)
+	if err != nil {
+		return "", err
+	}
+
+	if ok {
+		return user, nil
+	}
+
+	return "", fmt.Errorf("%s is not an owner of %s (owners: %s)", user, service, strings.Join(owners, ", "))
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+	"github.com/cygnetdigital/shipper/internal/source"
+)
+
+// ParamsChecker is implemented by destinations which can validate the
+// params services give their templates
+type ParamsChecker interface {
+	CheckParams(ctx context.Context, p *destination.DeployParams) error
+}
+
+// CheckParams validates the params of the source's services, if the
+// destination can, so a deploy with bad params fails before it waits for
+// builds or takes the lock
+func (h *LocalHandler) CheckParams(ctx context.Context, project string, src *source.Source) error {
+	checker, ok := h.Dest.(ParamsChecker)
+	if !ok {
+		return nil
+	}
+
+	p := &destination.DeployParams{ProjectName: project}
+
+	for _, svc := range src.Services {
+		p.Services = append(p.Services, &destination.ServiceDeployParams{Config: svc.Service})
+	}
+
+	return checker.CheckParams(ctx, p)
+}
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/cygnetdigital/shipper/internal/destination"
+)
+
+// DeployPlanner is implemented by destinations which can check a deploy
+// against their policies, and show how it changes the config of services,
+// without making it
+type DeployPlanner interface {
+	PlanDeploy(ctx context.Context, p *destination.DeployParams) (*destination.DeployPlan, error)
+}
+
+// planDeploy against the destination, or just get its state if it can't
+// plan deploys
+func (h *LocalHandler) planDeploy(ctx context.Context, p *destination.DeployParams) (*destination.DeployPlan, error) {
+	if planner, ok := h.Dest.(DeployPlanner); ok {
+		return planner.PlanDeploy(ctx, p)
+	}
+
+	dest, err := h.Dest.Get(ctx, p.ProjectName)
+	if err != nil {
+		return nil, err
+	}
+
+	return &destination.DeployPlan{Destination: dest}, nil
+}
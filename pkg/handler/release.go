@@ -20,6 +20,9 @@ type ReleaseParams struct {
 
 	// Confirm should be true to actually do the deploy
 	Confirm bool
+
+	// OverrideFreeze is the reason for releasing while the service is frozen
+	OverrideFreeze string
 }
 
 // ReleaseResp is the result from a Release
@@ -28,15 +31,35 @@ type ReleaseResp struct {
 	Service string
 	Version string
 	Done    bool
+
+	// Approval status if the release needs approving
+	Approval *ApprovalStatus
 }
 
 // Release ...
 func (h *LocalHandler) Release(ctx context.Context, p *ReleaseParams) (*ReleaseResp, error) {
+	if err := h.checkFreeze(ctx, p.Project, []string{p.Service}, p.OverrideFreeze); err != nil {
+		return nil, err
+	}
+
+	breakGlass, err := h.checkOwner(ctx, p.Service)
+	if err != nil {
+		return nil, err
+	}
+
 	if p.Confirm {
+		if err := h.checkReleaseApproved(ctx, p); err != nil {
+			return nil, err
+		}
+
 		relreq := &destination.ReleaseParams{
-			Project: p.Project,
-			Service: p.Service,
-			Version: p.Version,
+			Project:        p.Project,
+			Service:        p.Service,
+			Version:        p.Version,
+			FreezeOverride: p.OverrideFreeze,
+			Owners:         h.serviceOwners(p.Service),
+			BreakGlass:     breakGlass,
+			LockToken:      h.lockToken,
 		}
 
 		if _, err := h.Dest.Release(ctx, relreq); err != nil {
@@ -77,9 +100,43 @@ func (h *LocalHandler) Release(ctx context.Context, p *ReleaseParams) (*ReleaseR
 		return nil, fmt.Errorf("version %s not found", p.Version)
 	}
 
+	approval, err := h.checkApproval(ctx, p.Project, p.Service, p.Version, svc.CurrentReleaseVersion)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ReleaseResp{
-		Project: svc.Project,
-		Service: svc.Name,
-		Version: p.Version,
+		Project:  svc.Project,
+		Service:  svc.Name,
+		Version:  p.Version,
+		Approval: approval,
 	}, nil
 }
+
+// checkReleaseApproved refuses the release unless the policy is satisfied
+func (h *LocalHandler) checkReleaseApproved(ctx context.Context, p *ReleaseParams) error {
+	if requiredApprovals(h.approvalRule(p.Service)) == 0 {
+		return nil
+	}
+
+	dest, err := h.Dest.Get(ctx, p.Project)
+	if err != nil {
+		return fmt.Errorf("failed to get destination: %w", err)
+	}
+
+	svc := dest.Services.Lookup(p.Service)
+	if svc == nil {
+		return fmt.Errorf("service not found")
+	}
+
+	status, err := h.checkApproval(ctx, p.Project, p.Service, p.Version, svc.CurrentReleaseVersion)
+	if err != nil {
+		return err
+	}
+
+	if !status.Approved {
+		return fmt.Errorf("release has %d of %d approvals, run `shipper approve %s %s` to approve it", status.Have, status.Need, p.Service, p.Version)
+	}
+
+	return nil
+}
@@ -37,11 +37,19 @@ func (h *LocalHandler) Remove(ctx context.Context, p *RemoveParams) (*RemoveResp
 		return nil, fmt.Errorf("service and version are required")
 	}
 
+	breakGlass, err := h.checkOwner(ctx, p.Service)
+	if err != nil {
+		return nil, err
+	}
+
 	if p.Confirm {
 		remreq := &destination.RemoveParams{
-			Project: p.Project,
-			Service: p.Service,
-			Version: p.Version,
+			Project:    p.Project,
+			Service:    p.Service,
+			Version:    p.Version,
+			Owners:     h.serviceOwners(p.Service),
+			BreakGlass: breakGlass,
+			LockToken:  h.lockToken,
 		}
 
 		if _, err := h.Dest.Remove(ctx, remreq); err != nil {
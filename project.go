@@ -81,7 +81,7 @@ func LoadProject(pwd string) (*Project, error) {
 
 			pathRelToProject := strings.TrimPrefix(strings.TrimPrefix(sp, p), "/")
 
-			ctx.Services = append(ctx.Services, &Service{Service: &svc, RootDir: pathRelToProject})
+			ctx.Services = append(ctx.Services, &Service{Service: &svc, RootDir: pathRelToProject, Raw: bts})
 		}
 
 		return ctx, nil
@@ -10,6 +10,18 @@ import (
 type Service struct {
 	*conf.Service
 	RootDir string
+
+	// Raw config file, kept to decrypt sops encrypted secrets with
+	Raw []byte
+}
+
+// ImageName is the name of the service image under the registry prefix
+func (s *Service) ImageName() string {
+	if s.Build.Image.Name != "" {
+		return s.Build.Image.Name
+	}
+
+	return s.Name
 }
 
 // Services are the services in the project